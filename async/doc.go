@@ -39,6 +39,15 @@ It's important because you still have to be careful with shared resources.
 	// We also have an async.Await function if you prefer functional style.
 	val, err := async.Await(ftr)
 
+# Race / Any
+
+Race settles with the first settled future, Any - with the first successful one.
+
+	// First settled, even if failed.
+	val, err := async.Race(fetch(primary), fetch(replica)).Await()
+	// First successful, or the last error.
+	val, err := async.Any(fetch(primary), fetch(replica)).Await()
+
 # Map / Filter / Pool
 
 The package provides high-level functions to work with collections,
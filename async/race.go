@@ -0,0 +1,77 @@
+package async
+
+import "errors"
+
+var ErrRaceEmpty = errors.New("no futures to race")
+
+// raceResult holds a settled future result.
+type raceResult[T any] struct {
+	value T
+	err   error
+}
+
+// race awaits all futures in parallel, sending results into a buffered channel.
+// Each future is awaited only once, by its own goroutine.
+func race[T any](futures []*Future[T]) chan raceResult[T] {
+	results := make(chan raceResult[T], len(futures))
+	for _, f := range futures {
+		go func(f *Future[T]) {
+			value, err := f.Await()
+			results <- raceResult[T]{value, err}
+		}(f)
+	}
+
+	return results
+}
+
+/*
+Race returns a future, which settles with the first settled future
+(successful or not), like JavaScript Promise.race.
+Other futures are not cancelled, use context for that.
+Futures must not be awaited elsewhere concurrently.
+
+Usage:
+
+	val, err := async.Race(fetch(primary), fetch(replica)).Await()
+*/
+func Race[T any](futures ...*Future[T]) *Future[T] {
+	return New(func() (T, error) {
+		if len(futures) == 0 {
+			var zero T
+			return zero, ErrRaceEmpty
+		}
+		result := <-race(futures)
+
+		return result.value, result.err
+	})
+}
+
+/*
+Any returns a future, which resolves with the first successful future,
+like JavaScript Promise.any.
+If all futures fail, the last error is returned.
+Other futures are not cancelled, use context for that.
+Futures must not be awaited elsewhere concurrently.
+
+Usage:
+
+	val, err := async.Any(fetch(primary), fetch(replica)).Await()
+*/
+func Any[T any](futures ...*Future[T]) *Future[T] {
+	return New(func() (T, error) {
+		var (
+			zero T
+			err  error = ErrRaceEmpty
+		)
+		results := race(futures)
+		for range futures {
+			result := <-results
+			if result.err == nil {
+				return result.value, nil
+			}
+			err = result.err
+		}
+
+		return zero, err
+	})
+}
@@ -2,11 +2,15 @@ package atomicx
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 /*
-Value is an atomic value holder,
-which have internal rwmutex to avoid concurrent writes.
+Value is an atomic value holder.
+Reads are lock-free (value pointer is stored in atomic.Value),
+so it's cheap for read-heavy usage, like config holders.
+Writes are serialized with internal mutex,
+so read-modify-write operations (Update, Context) never lose concurrent writes.
 
 Usage:
 
@@ -17,20 +21,30 @@ Usage:
 	if val.Get() == 1 {
 		log.Println("value is 1")
 	}
+	val.Update(func(v int) int { return v + 1 }) // 2
 */
 type Value[T any] struct {
-	value T
-	lock  sync.RWMutex
+	value atomic.Value // *T
+	lock  sync.Mutex
 }
 
 /*
-Get locks value mutex and returns current value.
+Get returns current value without locking.
 */
 func (a *Value[T]) Get() T {
-	a.lock.RLock()
-	defer a.lock.RUnlock()
+	if ptr, ok := a.value.Load().(*T); ok {
+		return *ptr
+	}
+	var zero T
+
+	return zero
+}
 
-	return a.value
+/*
+Load is an alias of Get.
+*/
+func (a *Value[T]) Load() T {
+	return a.Get()
 }
 
 /*
@@ -39,22 +53,90 @@ Set locks value mutex and sets current value.
 func (a *Value[T]) Set(value T) {
 	a.lock.Lock()
 	defer a.lock.Unlock()
-	a.value = value
+
+	a.value.Store(&value)
+}
+
+/*
+Store is an alias of Set.
+*/
+func (a *Value[T]) Store(value T) {
+	a.Set(value)
+}
+
+/*
+Swap sets a new value and returns the previous one.
+*/
+func (a *Value[T]) Swap(value T) T {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	old := a.Get()
+	a.value.Store(&value)
+
+	return old
+}
+
+/*
+Update locks value mutex, sets a value, returned by fn, and returns it.
+Fn is called exactly once, with current value.
+
+Usage:
+
+	counter := atomicx.NewValue(0)
+	next := counter.Update(func(v int) int {
+		return v + 1
+	})
+*/
+func (a *Value[T]) Update(fn func(value T) T) T {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	value := fn(a.Get())
+	a.value.Store(&value)
+
+	return value
 }
 
 /*
 Context locks value mutex and executes provided function
 with releasing lock in the end.
+Consider using Update instead.
 */
 func (a *Value[T]) Context(c func(value T, set func(value T))) {
 	a.lock.Lock()
 	defer a.lock.Unlock()
 
 	setter := func(value T) {
-		a.value = value
+		a.value.Store(&value)
+	}
+
+	c(a.Get(), setter)
+}
+
+/*
+CompareAndSwap sets a new value, if current value equals to old one.
+Returns true if value was swapped.
+It's a function instead of a method,
+because it's available only for comparable types.
+
+Usage:
+
+	state := atomicx.NewValue("idle")
+	if atomicx.CompareAndSwap(state, "idle", "running") {
+		log.Println("started")
 	}
+*/
+func CompareAndSwap[T comparable](a *Value[T], old, new T) bool { //nolint:predeclared
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.Get() != old {
+		return false
+	}
+	a.value.Store(&new)
 
-	c(a.value, setter)
+	return true
 }
 
 /*
@@ -62,7 +144,8 @@ NewValue is a constructor of Value,
 which accepts default value.
 */
 func NewValue[T any](defaultval T) *Value[T] {
-	return &Value[T]{
-		value: defaultval,
-	}
+	value := &Value[T]{}
+	value.value.Store(&defaultval)
+
+	return value
 }
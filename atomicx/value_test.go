@@ -0,0 +1,95 @@
+package atomicx
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestValueZero(t *testing.T) {
+	value := &Value[int]{}
+	if v := value.Get(); v != 0 {
+		t.Fatalf("expected zero value, got %d", v)
+	}
+	var ptr Value[*int]
+	if v := ptr.Load(); v != nil {
+		t.Fatalf("expected nil pointer, got %v", v)
+	}
+}
+
+func TestValueLoadStore(t *testing.T) {
+	value := NewValue("a")
+	if v := value.Load(); v != "a" {
+		t.Fatalf("expected default value a, got %s", v)
+	}
+	value.Store("b")
+	if v := value.Get(); v != "b" {
+		t.Fatalf("expected b, got %s", v)
+	}
+}
+
+func TestValueUpdateConcurrent(t *testing.T) {
+	value := NewValue(0)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				value.Update(func(v int) int { return v + 1 })
+				value.Get()
+			}
+		}()
+	}
+	wg.Wait()
+	if v := value.Get(); v != 8000 {
+		t.Fatalf("expected 8000, got %d", v)
+	}
+}
+
+func TestValueSwap(t *testing.T) {
+	value := NewValue(1)
+	if old := value.Swap(2); old != 1 {
+		t.Fatalf("expected old value 1, got %d", old)
+	}
+	if v := value.Get(); v != 2 {
+		t.Fatalf("expected 2, got %d", v)
+	}
+	zero := &Value[int]{}
+	if old := zero.Swap(3); old != 0 {
+		t.Fatalf("expected old zero value, got %d", old)
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	state := NewValue("idle")
+	if !CompareAndSwap(state, "idle", "running") {
+		t.Fatal("expected swap from idle")
+	}
+	if CompareAndSwap(state, "idle", "stopped") {
+		t.Fatal("expected no swap from idle, while running")
+	}
+	if v := state.Get(); v != "running" {
+		t.Fatalf("expected running, got %s", v)
+	}
+	// Concurrent swaps, exactly one wins
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		wins int
+	)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if CompareAndSwap(state, "running", "stopped") {
+				lock.Lock()
+				wins++
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if wins != 1 {
+		t.Fatalf("expected exactly one swap, got %d", wins)
+	}
+}
@@ -0,0 +1,588 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+EvictReason describes why an entry was removed from the cache.
+*/
+type EvictReason int
+
+const (
+	// EvictExpired means entry TTL is over
+	EvictExpired EvictReason = iota
+	// EvictCapacity means entry was evicted by policy to fit capacity
+	EvictCapacity
+	// EvictDeleted means entry was deleted explicitly (Delete, Clear)
+	EvictDeleted
+	// EvictReplaced means entry value was replaced with Set
+	EvictReplaced
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictExpired:
+		return "expired"
+	case EvictCapacity:
+		return "capacity"
+	case EvictDeleted:
+		return "deleted"
+	case EvictReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+/*
+Config is a Cache configuration.
+All fields are optional.
+*/
+type Config[K comparable, V any] struct {
+	// TTL is a default entry TTL. Zero means no expiration
+	TTL time.Duration
+	// TTLFunc calculates TTL of values, loaded by GetOrLoad
+	// (f.e. from token expiration). Defaults to TTL
+	TTLFunc func(key K, value V) time.Duration
+	// MaxEntries limits a number of entries. Zero means no limit
+	MaxEntries int
+	// MaxCost limits a total cost of entries (e.g. bytes). Zero means no limit
+	MaxCost int64
+	// Cost calculates entry cost. Defaults to 1 per entry
+	Cost func(key K, value V) int64
+	// Policy is an eviction policy. Defaults to LRU
+	Policy PolicyFunc[K]
+	// Shards is a number of independently locked shards.
+	// Defaults to 16 for unlimited or large (1024+ entries) caches, and 1 otherwise.
+	// Capacity limits are split evenly between shards and enforced per shard,
+	// so a sharded cache might evict a key while other shards still have room
+	Shards int
+	// OnEvict is called on entry removal, outside of cache locks
+	OnEvict func(key K, value V, reason EvictReason)
+	// StaleTTL extends entry lifetime after TTL (soft expiration).
+	// Stale entries are still served, while GetOrLoad refreshes them in background
+	StaleTTL time.Duration
+	// RefreshAhead is a TTL fraction (0..1) to start background refresh
+	// in GetOrLoad, while the entry is still fresh
+	RefreshAhead float64
+}
+
+/*
+Cache is a generic in-memory key/value cache
+with per-entry TTL, capacity limits by count or cost,
+pluggable eviction policy (LRU, LFU, TinyLFU) and sharding.
+It's safe for concurrent use.
+Expired entries are removed lazily, on access or eviction,
+use DeleteExpired to remove them proactively.
+Use New to create it.
+
+Usage:
+
+	users := cache.New(cache.Config[int, *User]{
+		TTL:        10 * time.Minute,
+		MaxEntries: 10000,
+		Policy:     cache.TinyLFU[int],
+	})
+	users.Set(42, user)
+	user, ok := users.Get(42)
+	user, err := users.GetOrLoad(42, func(id int) (*User, error) {
+		return db.User(id)
+	})
+*/
+type Cache[K comparable, V any] struct {
+	config Config[K, V]
+	shards []*shard[K, V]
+	stats  *counters
+}
+
+// entry is a cached value.
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	expires    time.Time
+	refresh    time.Time // zero if no background refresh needed
+	refreshing bool
+	cost       int64
+}
+
+// expired checks entry expiration.
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// eviction is a removed entry, reported to OnEvict outside of lock.
+type eviction[K comparable, V any] struct {
+	entry  *entry[K, V]
+	reason EvictReason
+}
+
+// shard is an independently locked cache part.
+type shard[K comparable, V any] struct {
+	lock       sync.Mutex
+	entries    map[K]*entry[K, V]
+	policy     Policy[K]
+	cost       int64
+	maxEntries int
+	maxCost    int64
+	flight     flight[K, V]
+}
+
+// remove drops an entry. Must be called under lock.
+func (s *shard[K, V]) remove(e *entry[K, V], reason EvictReason, evicted []eviction[K, V]) []eviction[K, V] {
+	delete(s.entries, e.key)
+	s.cost -= e.cost
+	s.policy.Remove(e.key)
+
+	return append(evicted, eviction[K, V]{e, reason})
+}
+
+// fit evicts entries to fit capacity. Must be called under lock.
+func (s *shard[K, V]) fit(now time.Time, evicted []eviction[K, V]) []eviction[K, V] {
+	for (s.maxEntries > 0 && len(s.entries) > s.maxEntries) || (s.maxCost > 0 && s.cost > s.maxCost) {
+		key, ok := s.policy.Evict()
+		if !ok {
+			break
+		}
+		e, ok := s.entries[key]
+		if !ok {
+			continue
+		}
+		reason := EvictCapacity
+		if e.expired(now) {
+			reason = EvictExpired
+		}
+		delete(s.entries, key)
+		s.cost -= e.cost
+		evicted = append(evicted, eviction[K, V]{e, reason})
+	}
+
+	return evicted
+}
+
+// shard returns a key shard.
+func (c *Cache[K, V]) shard(key K) *shard[K, V] {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+
+	return c.shards[hashKey(key)%uint64(len(c.shards))]
+}
+
+// notify counts evictions and reports them to OnEvict.
+func (c *Cache[K, V]) notify(evicted []eviction[K, V]) {
+	for _, e := range evicted {
+		c.stats.evict(e.reason)
+		if c.config.OnEvict != nil {
+			c.config.OnEvict(e.entry.key, e.entry.value, e.reason)
+		}
+	}
+}
+
+/*
+Get returns a cached value and true, or false if missing or expired.
+*/
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	value, _, ok := c.lookup(key)
+
+	return value, ok
+}
+
+// lookup returns a cached value and whether caller must refresh it in background.
+// Refresh is requested only once per entry, until done or failed.
+func (c *Cache[K, V]) lookup(key K) (V, bool, bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	// Miss
+	e, ok := s.entries[key]
+	if !ok {
+		s.policy.Access(key)
+		s.lock.Unlock()
+		c.stats.hit(false)
+		var zero V
+		return zero, false, false
+	}
+	// Expired
+	now := time.Now()
+	if e.expired(now) {
+		evicted := s.remove(e, EvictExpired, nil)
+		s.lock.Unlock()
+		c.notify(evicted)
+		c.stats.hit(false)
+		var zero V
+		return zero, false, false
+	}
+	// Hit
+	s.policy.Access(key)
+	refresh := !e.refresh.IsZero() && !now.Before(e.refresh) && !e.refreshing
+	if refresh {
+		e.refreshing = true
+	}
+	s.lock.Unlock()
+	c.stats.hit(true)
+
+	return e.value, refresh, true
+}
+
+// peek returns a not expired value without touching policy and statistics.
+func (c *Cache[K, V]) peek(key K) (V, bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.entries[key]; ok && !e.expired(time.Now()) {
+		return e.value, true
+	}
+	var zero V
+
+	return zero, false
+}
+
+// unmark allows to request entry refresh again, after a failed one.
+func (c *Cache[K, V]) unmark(key K) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.refreshing = false
+	}
+}
+
+/*
+Set stores a value with a default TTL.
+*/
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.config.TTL)
+}
+
+/*
+SetWithTTL stores a value with a given TTL.
+Zero TTL means no expiration.
+If StaleTTL is configured, entry is kept for TTL + StaleTTL.
+*/
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	var (
+		now = time.Now()
+		e   = &entry[K, V]{key: key, value: value, cost: 1}
+	)
+	if ttl > 0 {
+		e.expires = now.Add(ttl + c.config.StaleTTL)
+		switch {
+		case c.config.RefreshAhead > 0 && c.config.RefreshAhead < 1:
+			e.refresh = now.Add(time.Duration(float64(ttl) * c.config.RefreshAhead))
+		case c.config.StaleTTL > 0:
+			e.refresh = now.Add(ttl)
+		}
+	}
+	c.set(e, now)
+}
+
+// set stores a prepared entry.
+func (c *Cache[K, V]) set(e *entry[K, V], now time.Time) {
+	key := e.key
+	if c.config.Cost != nil {
+		e.cost = c.config.Cost(key, e.value)
+	}
+	s := c.shard(key)
+	s.lock.Lock()
+	var evicted []eviction[K, V]
+	// Replace or add
+	if old, ok := s.entries[key]; ok {
+		s.cost -= old.cost
+		s.policy.Access(key)
+		evicted = append(evicted, eviction[K, V]{old, EvictReplaced})
+	} else {
+		s.policy.Add(key)
+	}
+	s.entries[key] = e
+	s.cost += e.cost
+	// Evict over capacity
+	evicted = s.fit(now, evicted)
+	s.lock.Unlock()
+	c.notify(evicted)
+}
+
+/*
+Delete removes a value.
+Returns true if value was present.
+*/
+func (c *Cache[K, V]) Delete(key K) bool {
+	s := c.shard(key)
+	s.lock.Lock()
+	e, ok := s.entries[key]
+	if !ok {
+		s.lock.Unlock()
+		return false
+	}
+	evicted := s.remove(e, EvictDeleted, nil)
+	s.lock.Unlock()
+	c.notify(evicted)
+
+	return true
+}
+
+/*
+GetOrLoad returns a cached value, or loads and stores it with a default TTL (or TTLFunc).
+Loads are de-duplicated per key, concurrent callers are waiting for a single load.
+Stale (see StaleTTL) or about to expire (see RefreshAhead) values are returned immediately,
+while a new value is loaded in background.
+Errors are not cached, failed background refresh keeps the old value.
+*/
+func (c *Cache[K, V]) GetOrLoad(key K, loader func(K) (V, error)) (V, error) {
+	if value, refresh, ok := c.lookup(key); ok {
+		if refresh {
+			c.refresh(key, loader)
+		}
+		return value, nil
+	}
+
+	return c.load(key, loader, false)
+}
+
+/*
+GetOrRefresh returns a cached value and true, or false if missing or expired.
+Unlike Get, stale or about to expire values are refreshed in background (like in GetOrLoad),
+while missing values are not loaded.
+Useful to serve hits synchronously and to load misses elsewhere.
+*/
+func (c *Cache[K, V]) GetOrRefresh(key K, loader func(K) (V, error)) (V, bool) {
+	value, refresh, ok := c.lookup(key)
+	if refresh {
+		c.refresh(key, loader)
+	}
+
+	return value, ok
+}
+
+// load loads a value, once per key for concurrent callers.
+func (c *Cache[K, V]) load(key K, loader func(K) (V, error), background bool) (V, error) {
+	return c.shard(key).flight.Do(key, func() (V, error) {
+		// Value might be stored by a load, finished in between
+		if !background {
+			if value, ok := c.peek(key); ok {
+				return value, nil
+			}
+		}
+		start := time.Now()
+		value, err := loader(key)
+		c.stats.load(start, err)
+		if err == nil {
+			c.SetWithTTL(key, value, c.ttl(key, value))
+		}
+		return value, err
+	})
+}
+
+// ttl returns loaded value TTL.
+func (c *Cache[K, V]) ttl(key K, value V) time.Duration {
+	if c.config.TTLFunc != nil {
+		return c.config.TTLFunc(key, value)
+	}
+
+	return c.config.TTL
+}
+
+// refresh loads a value in background.
+func (c *Cache[K, V]) refresh(key K, loader func(K) (V, error)) {
+	go func() {
+		if _, err := c.load(key, loader, true); err != nil {
+			c.unmark(key)
+		}
+	}()
+}
+
+/*
+Range calls fn for each not expired entry, until fn returns false.
+Entries are collected per shard before calling fn,
+so fn might use the cache.
+*/
+func (c *Cache[K, V]) Range(fn func(key K, value V) bool) {
+	now := time.Now()
+	for _, s := range c.shards {
+		s.lock.Lock()
+		entries := make([]*entry[K, V], 0, len(s.entries))
+		for _, e := range s.entries {
+			if !e.expired(now) {
+				entries = append(entries, e)
+			}
+		}
+		s.lock.Unlock()
+		for _, e := range entries {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+/*
+Len returns a number of entries (including expired, but not yet removed).
+*/
+func (c *Cache[K, V]) Len() int {
+	count := 0
+	for _, s := range c.shards {
+		s.lock.Lock()
+		count += len(s.entries)
+		s.lock.Unlock()
+	}
+
+	return count
+}
+
+/*
+Cost returns a total cost of entries.
+*/
+func (c *Cache[K, V]) Cost() int64 {
+	var cost int64
+	for _, s := range c.shards {
+		s.lock.Lock()
+		cost += s.cost
+		s.lock.Unlock()
+	}
+
+	return cost
+}
+
+/*
+DeleteExpired removes expired entries.
+Returns a number of removed entries.
+*/
+func (c *Cache[K, V]) DeleteExpired() int {
+	var (
+		now   = time.Now()
+		count = 0
+	)
+	for _, s := range c.shards {
+		s.lock.Lock()
+		var evicted []eviction[K, V]
+		for _, e := range s.entries {
+			if e.expired(now) {
+				evicted = s.remove(e, EvictExpired, evicted)
+			}
+		}
+		s.lock.Unlock()
+		c.notify(evicted)
+		count += len(evicted)
+	}
+
+	return count
+}
+
+/*
+Dump calls fn for each not expired entry with its expiration time
+(zero if entry doesn't expire), until fn returns false.
+Check WriteSnapshot for details.
+*/
+func (c *Cache[K, V]) Dump(fn func(key K, value V, expires time.Time) bool) {
+	now := time.Now()
+	for _, s := range c.shards {
+		// Collect shard entries
+		s.lock.Lock()
+		entries := make([]entry[K, V], 0, len(s.entries))
+		for _, e := range s.entries {
+			if !e.expired(now) {
+				entries = append(entries, *e)
+			}
+		}
+		s.lock.Unlock()
+		// Call
+		for _, e := range entries {
+			if !fn(e.key, e.value, e.expires) {
+				return
+			}
+		}
+	}
+}
+
+/*
+Restore stores a value with a given expiration time (zero means no expiration).
+Expired values are ignored.
+With StaleTTL, expiration is treated as a hard one.
+Check ReadSnapshot for details.
+*/
+func (c *Cache[K, V]) Restore(key K, value V, expires time.Time) error {
+	now := time.Now()
+	if !expires.IsZero() && !now.Before(expires) {
+		return nil
+	}
+	e := &entry[K, V]{key: key, value: value, expires: expires, cost: 1}
+	if !expires.IsZero() && c.config.StaleTTL > 0 {
+		e.refresh = expires.Add(-c.config.StaleTTL)
+	}
+	c.set(e, now)
+
+	return nil
+}
+
+/*
+Stats returns cache statistics.
+Loads are counted only for GetOrLoad.
+*/
+func (c *Cache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+/*
+Clear removes all entries.
+*/
+func (c *Cache[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.lock.Lock()
+		var evicted []eviction[K, V]
+		for _, e := range s.entries {
+			evicted = s.remove(e, EvictDeleted, evicted)
+		}
+		s.lock.Unlock()
+		c.notify(evicted)
+	}
+}
+
+/*
+New is a Cache builder.
+Check Cache and Config for details.
+
+Usage:
+
+	c := cache.New(cache.Config[string, []byte]{
+		TTL:     time.Minute,
+		MaxCost: 64 << 20, // 64MB
+		Cost:    func(key string, value []byte) int64 { return int64(len(value)) },
+		OnEvict: func(key string, value []byte, reason cache.EvictReason) {
+			log.Println("evicted", key, reason)
+		},
+	})
+*/
+func New[K comparable, V any](config ...Config[K, V]) *Cache[K, V] {
+	if len(config) == 0 {
+		config = append(config, Config[K, V]{})
+	}
+	cfg := config[0]
+	// Defaults
+	if cfg.Policy == nil {
+		cfg.Policy = LRU[K]
+	}
+	shards := cfg.Shards
+	if shards <= 0 {
+		shards = 1
+		if cfg.MaxCost == 0 && (cfg.MaxEntries == 0 || cfg.MaxEntries >= 1024) {
+			shards = 16
+		}
+	}
+	// Create shards, splitting capacity
+	cache := &Cache[K, V]{config: cfg, shards: make([]*shard[K, V], shards), stats: &counters{}}
+	for i := range cache.shards {
+		s := &shard[K, V]{entries: map[K]*entry[K, V]{}}
+		if cfg.MaxEntries > 0 {
+			s.maxEntries = (cfg.MaxEntries + shards - 1) / shards
+		}
+		if cfg.MaxCost > 0 {
+			s.maxCost = (cfg.MaxCost + int64(shards) - 1) / int64(shards)
+		}
+		s.policy = cfg.Policy(s.maxEntries)
+		cache.shards[i] = s
+	}
+
+	return cache
+}
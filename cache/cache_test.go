@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheCapacity(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		evicted []int
+	)
+	c := New(Config[int, int]{
+		MaxEntries: 2,
+		OnEvict: func(key int, value int, reason EvictReason) {
+			lock.Lock()
+			defer lock.Unlock()
+			if reason == EvictCapacity {
+				evicted = append(evicted, key)
+			}
+		},
+	})
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Get(1)
+	c.Set(3, 3)
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+	if len(evicted) != 1 || evicted[0] != 2 {
+		t.Fatalf("expected least recently used key 2 to be evicted, got %v", evicted)
+	}
+	if _, ok := c.Get(1); !ok {
+		t.Fatalf("expected key 1 to stay")
+	}
+}
+
+func TestCacheMaxCost(t *testing.T) {
+	c := New(Config[string, string]{
+		MaxCost: 10,
+		Cost: func(key string, value string) int64 {
+			return int64(len(value))
+		},
+	})
+	c.Set("a", "12345")
+	c.Set("b", "12345")
+	c.Set("c", "123")
+	if c.Cost() > 10 {
+		t.Fatalf("expected cost to fit 10, got %d", c.Cost())
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("expected key a to be evicted")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := New(Config[string, int]{TTL: 20 * time.Millisecond})
+	c.Set("key", 1)
+	c.SetWithTTL("forever", 2, 0)
+	if _, ok := c.Get("key"); !ok {
+		t.Fatalf("expected key to be cached")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := c.Get("key"); ok {
+		t.Fatalf("expected key to expire")
+	}
+	if _, ok := c.Get("forever"); !ok {
+		t.Fatalf("expected key without TTL to stay")
+	}
+}
+
+func TestCacheTTLFunc(t *testing.T) {
+	c := New(Config[string, time.Duration]{
+		TTL: time.Hour,
+		TTLFunc: func(key string, value time.Duration) time.Duration {
+			return value
+		},
+	})
+	loader := func(string) (time.Duration, error) { return 20 * time.Millisecond, nil }
+	c.GetOrLoad("key", loader) //nolint:errcheck
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := c.Get("key"); ok {
+		t.Fatalf("expected key to expire with loaded TTL")
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := New(Config[string, int]{MaxEntries: 2048, Policy: TinyLFU[string]})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := fmt.Sprintf("key%d", (g*1000+i)%3000)
+				c.GetOrLoad(key, func(string) (int, error) { return i, nil }) //nolint:errcheck
+				if i%10 == 0 {
+					c.Delete(key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// Capacity is split between shards, so it's never exceeded
+	if c.Len() > 2048 {
+		t.Fatalf("expected at most 2048 entries, got %d", c.Len())
+	}
+}
+
+func TestCacheRefreshDoesNotLeak(t *testing.T) {
+	c := New(Config[string, int]{TTL: 10 * time.Millisecond, StaleTTL: time.Minute, Shards: 1})
+	loader := func(key string) (int, error) {
+		return 1, nil
+	}
+	c.GetOrLoad("key", loader) //nolint:errcheck
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		time.Sleep(15 * time.Millisecond)
+		c.GetOrLoad("key", loader) //nolint:errcheck
+	}
+	time.Sleep(20 * time.Millisecond)
+	if after := runtime.NumGoroutine(); after > before+1 {
+		t.Fatalf("expected background refreshes to finish, goroutines %d -> %d", before, after)
+	}
+}
+
+func TestCacheGetOrRefresh(t *testing.T) {
+	var (
+		lock  sync.Mutex
+		loads = 0
+	)
+	c := New(Config[string, int]{TTL: 100 * time.Millisecond, RefreshAhead: 0.1})
+	loader := func(key string) (int, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		loads++
+		return loads, nil
+	}
+	// Missing values are not loaded
+	if _, ok := c.GetOrRefresh("key", loader); ok {
+		t.Fatal("expected a miss")
+	}
+	c.Set("key", 0)
+	time.Sleep(20 * time.Millisecond)
+	// Stale value is returned, while refreshed in background
+	if value, ok := c.GetOrRefresh("key", loader); !ok || value != 0 {
+		t.Fatalf("expected value 0 during refresh, got %d", value)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if value, ok := c.GetOrRefresh("key", loader); !ok || value != 1 {
+		t.Fatalf("expected refreshed value 1, got %d", value)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if loads != 1 {
+		t.Fatalf("expected 1 load, got %d", loads)
+	}
+}
@@ -1,45 +1,86 @@
 package cache
 
-import "time"
+import (
+	"time"
+)
 
 /*
 CachedFunc is a cached function wrapper with exprire duration setting.
 Consider it as a cached getter.
-As far as CachedFunc doesn't support functions with arguments
-(it will require much more effort and complications),
-you'll need to create cached getter for each argument set.
-At least, until the creation of more advanced cached builder.
+It's safe for concurrent use, only one load is in flight at a time,
+while other callers are waiting for it.
+To avoid latency spikes on expiration,
+use WithStaleTTL (stale-while-revalidate) or WithRefreshAhead options.
+For functions with arguments, use Memoize.
 */
 type CachedFunc[T any] func() (T, error)
 
 /*
 NewCachedFunc is a CachedFunc builder.
 Check CachedFunc for details.
+Use NewCached if you need to invalidate cached value.
 
 Usage:
 
 	getter := NewCachedFunc(1 * time.Minute, func() (string, error) {
 		time.Sleep(1 * time.Second) Imitate some work
 		return "Function cached result"
-	})
+	}, cache.WithErrorTTL(5 * time.Second))
 	log.Println(getter()) // Takes some time
 	log.Println(getter()) // Gets a value from cache
 */
-func NewCachedFunc[T any](expire time.Duration, fn CachedFunc[T]) CachedFunc[T] {
-	// Define variables
-	var (
-		err       error
-		value     T
-		lastfetch time.Time
-	)
-	// Return getter
-	return func() (T, error) {
-		// Update value/err cache if expired
-		if time.Since(lastfetch) > expire {
-			value, err = fn()
-			lastfetch = time.Now()
-		}
-		// Return from cache
-		return value, err
+func NewCachedFunc[T any](expire time.Duration, fn CachedFunc[T], opts ...Option) CachedFunc[T] {
+	return NewCached(expire, fn, opts...).Get
+}
+
+/*
+Cached is a cached getter, which is used under the hood of CachedFunc.
+Unlike CachedFunc, it allows to invalidate cached value.
+It's a single-key Memo.
+Use NewCached to create it.
+*/
+type Cached[T any] struct {
+	memo *Memo[struct{}, T]
+}
+
+/*
+Get returns a cached value, or loads a new one if expired.
+*/
+func (c *Cached[T]) Get() (T, error) {
+	return c.memo.Get(struct{}{})
+}
+
+/*
+Invalidate drops a cached value, so the next Get will load a new one.
+Result of a load, which is in flight, is not cached.
+*/
+func (c *Cached[T]) Invalidate() {
+	c.memo.InvalidateAll()
+}
+
+/*
+Stats returns cached getter statistics.
+*/
+func (c *Cached[T]) Stats() Stats {
+	return c.memo.Stats()
+}
+
+/*
+NewCached is a Cached builder.
+Check Cached and CachedFunc for details.
+
+Usage:
+
+	cached := cache.NewCached(1 * time.Minute, func() (string, error) {
+		return "Function cached result", nil
+	})
+	log.Println(cached.Get())
+	cached.Invalidate()
+*/
+func NewCached[T any](expire time.Duration, fn func() (T, error), opts ...Option) *Cached[T] {
+	return &Cached[T]{
+		memo: NewMemo(expire, func(struct{}) (T, error) {
+			return fn()
+		}, opts...),
 	}
 }
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+/*
+Codec serializes cached values for persistent stores (like DiskStore).
+Built-in codecs are JSONCodec and GobCodec.
+*/
+type Codec interface {
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte, value any) error
+}
+
+/*
+JSONCodec is a Codec, based on encoding/json.
+Only exported fields are serialized.
+*/
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(value any) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec) Unmarshal(data []byte, value any) error {
+	return json.Unmarshal(data, value)
+}
+
+/*
+GobCodec is a Codec, based on encoding/gob.
+It's more compact and faster than JSON for Go-only usage,
+interface values must be registered with gob.Register.
+*/
+type GobCodec struct{}
+
+func (GobCodec) Marshal(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte, value any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
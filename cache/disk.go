@@ -0,0 +1,564 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDiskEntryTooLarge = errors.New("entry is larger than store size limit")
+	ErrDiskKeyMismatch   = errors.New("entry file key doesn't match its name")
+)
+
+// Disk store file layout.
+const (
+	diskTempPrefix = ".tmp-"
+	diskHeaderSize = 8 // expiration, unix nanoseconds (0 means no expiration)
+)
+
+/*
+DiskConfig is a DiskStore configuration.
+Only Dir is required.
+*/
+type DiskConfig struct {
+	// Dir is a store directory, created if not exists
+	Dir string
+	// Codec serializes keys and values (JSONCodec by default)
+	Codec Codec
+	// TTL is a default entry TTL, zero means no expiration
+	TTL time.Duration
+	// MaxSize limits total size of entry files in bytes,
+	// least recently used entries are evicted on overflow.
+	// Zero means no limit
+	MaxSize int64
+}
+
+// diskRecord is a serialized entry.
+type diskRecord[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// diskEntry is an indexed entry file.
+type diskEntry struct {
+	name    string
+	size    int64
+	expires time.Time
+}
+
+/*
+DiskStore is a persistent key/value store, which keeps values across process restarts.
+Each entry is stored in a separate file, named with a key hash.
+Keys are hashed by their text (encoding.TextMarshaler) or Go-syntax ("%#v") representation,
+so keys must not contain pointers to be found after restart.
+Writes are atomic (temporary file + rename).
+Files are read and written outside of store lock, only index updates are serialized.
+Entries are indexed in memory on open, to enforce size limit without directory scans.
+Last access time is kept as file modification time, so LRU order survives restarts.
+It's safe for concurrent use within a process,
+but the directory must not be shared between processes.
+Use NewDiskStore to create it.
+*/
+type DiskStore[K comparable, V any] struct {
+	config DiskConfig
+	flight flight[K, V]
+	stats  *counters
+
+	lock    sync.Mutex
+	entries map[string]*list.Element // file name -> *diskEntry
+	order   *list.List               // most recently used at the front
+	size    int64
+}
+
+// name returns a file name for a key.
+// Key representation doesn't depend on codec, because codec output
+// might be unstable across processes (f.e. gob type ids).
+func (s *DiskStore[K, V]) name(key K) (string, error) {
+	var data []byte
+	switch k := any(key).(type) {
+	case string:
+		data = []byte(k)
+	case encoding.TextMarshaler:
+		text, err := k.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		data = text
+	default:
+		data = []byte(fmt.Sprintf("%#v", key))
+	}
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// path returns a file path for a file name.
+func (s *DiskStore[K, V]) path(name string) string {
+	return filepath.Join(s.config.Dir, name)
+}
+
+/*
+Get returns a stored value and true, or false if missing or expired.
+Unreadable entries are removed and reported as missing.
+*/
+func (s *DiskStore[K, V]) Get(key K) (V, bool) {
+	value, ok := s.get(key)
+	s.stats.hit(ok)
+
+	return value, ok
+}
+
+// get returns a stored value.
+// File is read outside of lock, index is checked again after reading.
+func (s *DiskStore[K, V]) get(key K) (V, bool) {
+	var zero V
+	name, err := s.name(key)
+	if err != nil {
+		return zero, false
+	}
+	// Check index
+	s.lock.Lock()
+	element, ok := s.entries[name]
+	if !ok {
+		s.lock.Unlock()
+		return zero, false
+	}
+	entry := element.Value.(*diskEntry) //nolint:forcetypeassert
+	if entry.expired(time.Now()) {
+		s.remove(element, EvictExpired)
+		s.lock.Unlock()
+		return zero, false
+	}
+	s.lock.Unlock()
+	// Read and decode.
+	// Keys are compared by encoded hash,
+	// because decoded keys might differ from original ones (f.e. time.Time)
+	record, err := s.read(name)
+	if err == nil {
+		var decoded string
+		if decoded, err = s.name(record.Key); err == nil && decoded != name {
+			err = ErrDiskKeyMismatch
+		}
+	}
+	s.lock.Lock()
+	// Entry might be replaced or removed while reading
+	current, ok := s.entries[name]
+	if !ok || current.Value != entry {
+		s.lock.Unlock()
+		if ok {
+			return s.get(key)
+		}
+		return zero, false
+	}
+	if err != nil {
+		s.remove(current, EvictDeleted)
+		s.lock.Unlock()
+		return zero, false
+	}
+	// Track access
+	s.order.MoveToFront(current)
+	s.lock.Unlock()
+	now := time.Now()
+	os.Chtimes(s.path(name), now, now) //nolint:errcheck
+
+	return record.Value, true
+}
+
+// read reads and decodes an entry file.
+func (s *DiskStore[K, V]) read(name string) (diskRecord[K, V], error) {
+	record := diskRecord[K, V]{}
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		return record, err
+	}
+	if len(data) < diskHeaderSize {
+		return record, io.ErrUnexpectedEOF
+	}
+	err = s.config.Codec.Unmarshal(data[diskHeaderSize:], &record)
+
+	return record, err
+}
+
+/*
+Set stores a value with a default TTL.
+*/
+func (s *DiskStore[K, V]) Set(key K, value V) error {
+	return s.SetWithTTL(key, value, s.config.TTL)
+}
+
+/*
+SetWithTTL stores a value with a given TTL.
+Zero TTL means no expiration.
+Least recently used entries are evicted, if size limit is exceeded.
+*/
+func (s *DiskStore[K, V]) SetWithTTL(key K, value V, ttl time.Duration) error {
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	return s.set(key, value, expires)
+}
+
+// set stores a value with a given expiration time.
+func (s *DiskStore[K, V]) set(key K, value V, expires time.Time) error {
+	name, err := s.name(key)
+	if err != nil {
+		return err
+	}
+	// Encode
+	payload, err := s.config.Codec.Marshal(diskRecord[K, V]{Key: key, Value: value})
+	if err != nil {
+		return err
+	}
+	entry := &diskEntry{name: name, size: int64(diskHeaderSize + len(payload)), expires: expires}
+	if s.config.MaxSize > 0 && entry.size > s.config.MaxSize {
+		return ErrDiskEntryTooLarge
+	}
+	data := make([]byte, diskHeaderSize, entry.size)
+	if !entry.expires.IsZero() {
+		binary.BigEndian.PutUint64(data, uint64(entry.expires.UnixNano()))
+	}
+	data = append(data, payload...)
+	// Write temporary file outside of lock
+	tmp, err := s.write(data)
+	if err != nil {
+		return err
+	}
+	// Publish and index under lock, to keep files and index consistent
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := os.Rename(tmp, s.path(name)); err != nil {
+		os.Remove(tmp) //nolint:errcheck
+		return err
+	}
+	// Index, replacing existing entry
+	if element, ok := s.entries[name]; ok {
+		s.size -= element.Value.(*diskEntry).size //nolint:forcetypeassert
+		s.order.Remove(element)
+		s.stats.evict(EvictReplaced)
+	}
+	s.entries[name] = s.order.PushFront(entry)
+	s.size += entry.size
+	// Evict
+	for s.config.MaxSize > 0 && s.size > s.config.MaxSize {
+		s.remove(s.order.Back(), EvictCapacity)
+	}
+
+	return nil
+}
+
+// write writes and syncs a temporary file, which is renamed into entry file later.
+// Returns the temporary file path.
+func (s *DiskStore[K, V]) write(data []byte) (string, error) {
+	tmp, err := os.CreateTemp(s.config.Dir, diskTempPrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
+
+// remove removes an indexed entry file.
+// Unlink is done under lock, so it can't remove a newer file of the same entry.
+func (s *DiskStore[K, V]) remove(element *list.Element, reason EvictReason) {
+	entry := element.Value.(*diskEntry) //nolint:forcetypeassert
+	os.Remove(s.path(entry.name))       //nolint:errcheck
+	s.order.Remove(element)
+	delete(s.entries, entry.name)
+	s.size -= entry.size
+	s.stats.evict(reason)
+}
+
+/*
+Delete removes a value.
+*/
+func (s *DiskStore[K, V]) Delete(key K) error {
+	name, err := s.name(key)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if element, ok := s.entries[name]; ok {
+		s.remove(element, EvictDeleted)
+	}
+
+	return nil
+}
+
+/*
+GetOrLoad returns a stored value, or loads and stores it with a default TTL.
+Loads are de-duplicated per key.
+Errors are not stored, store write errors are ignored
+(loaded value is returned anyway).
+*/
+func (s *DiskStore[K, V]) GetOrLoad(key K, loader func(K) (V, error)) (V, error) {
+	if value, ok := s.Get(key); ok {
+		return value, nil
+	}
+
+	return s.flight.Do(key, func() (V, error) {
+		start := time.Now()
+		value, err := loader(key)
+		s.stats.load(start, err)
+		if err == nil {
+			s.Set(key, value) //nolint:errcheck
+		}
+		return value, err
+	})
+}
+
+/*
+Range calls fn for each non-expired entry, until fn returns false.
+Entries are read from disk, unreadable ones are skipped.
+*/
+func (s *DiskStore[K, V]) Range(fn func(key K, value V) bool) {
+	s.Dump(func(key K, value V, expires time.Time) bool {
+		return fn(key, value)
+	})
+}
+
+/*
+Dump calls fn for each non-expired entry with its expiration time
+(zero if entry doesn't expire), until fn returns false.
+Check WriteSnapshot for details.
+*/
+func (s *DiskStore[K, V]) Dump(fn func(key K, value V, expires time.Time) bool) {
+	s.lock.Lock()
+	names := make([]string, 0, len(s.entries))
+	for name := range s.entries {
+		names = append(names, name)
+	}
+	s.lock.Unlock()
+
+	now := time.Now()
+	for _, name := range names {
+		s.lock.Lock()
+		element, ok := s.entries[name]
+		if !ok || element.Value.(*diskEntry).expired(now) { //nolint:forcetypeassert
+			s.lock.Unlock()
+			continue
+		}
+		expires := element.Value.(*diskEntry).expires //nolint:forcetypeassert
+		s.lock.Unlock()
+		record, err := s.read(name)
+		if err != nil {
+			continue
+		}
+		if !fn(record.Key, record.Value, expires) {
+			return
+		}
+	}
+}
+
+/*
+Restore stores a value with a given expiration time (zero means no expiration).
+Expired values are ignored.
+Check ReadSnapshot for details.
+*/
+func (s *DiskStore[K, V]) Restore(key K, value V, expires time.Time) error {
+	if !expires.IsZero() && !time.Now().Before(expires) {
+		return nil
+	}
+
+	return s.set(key, value, expires)
+}
+
+// expired checks if entry is expired.
+func (e *diskEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+/*
+Len returns a number of stored entries (including expired, but not yet removed).
+*/
+func (s *DiskStore[K, V]) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.entries)
+}
+
+/*
+Size returns total size of entry files in bytes.
+*/
+func (s *DiskStore[K, V]) Size() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.size
+}
+
+/*
+Stats returns store statistics.
+Loads are counted only for GetOrLoad.
+*/
+func (s *DiskStore[K, V]) Stats() Stats {
+	return s.stats.snapshot()
+}
+
+/*
+DeleteExpired removes all expired entries.
+*/
+func (s *DiskStore[K, V]) DeleteExpired() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	for element := s.order.Back(); element != nil; {
+		prev := element.Prev()
+		if element.Value.(*diskEntry).expired(now) { //nolint:forcetypeassert
+			s.remove(element, EvictExpired)
+		}
+		element = prev
+	}
+}
+
+/*
+Clear removes all entries.
+*/
+func (s *DiskStore[K, V]) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for element := s.order.Back(); element != nil; element = s.order.Back() {
+		s.remove(element, EvictDeleted)
+	}
+}
+
+// scan indexes existing entry files, removing expired and leftover temporary ones.
+func (s *DiskStore[K, V]) scan() error {
+	files, err := os.ReadDir(s.config.Dir)
+	if err != nil {
+		return err
+	}
+	var (
+		now     = time.Now()
+		entries = make([]*diskEntry, 0, len(files))
+		atimes  = make(map[*diskEntry]time.Time, len(files))
+	)
+	for _, file := range files {
+		path := s.path(file.Name())
+		// Skip directories and foreign files
+		if file.IsDir() || len(file.Name()) != sha256.Size*2 {
+			if strings.HasPrefix(file.Name(), diskTempPrefix) {
+				os.Remove(path) //nolint:errcheck
+			}
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		// Read expiration header
+		expires, err := diskExpires(path)
+		if err != nil {
+			continue
+		}
+		entry := &diskEntry{name: file.Name(), size: info.Size(), expires: expires}
+		if entry.expired(now) {
+			os.Remove(path) //nolint:errcheck
+			continue
+		}
+		entries = append(entries, entry)
+		atimes[entry] = info.ModTime()
+	}
+	// Restore LRU order
+	sort.Slice(entries, func(a, b int) bool {
+		return atimes[entries[a]].After(atimes[entries[b]])
+	})
+	for _, entry := range entries {
+		s.entries[entry.name] = s.order.PushBack(entry)
+		s.size += entry.size
+	}
+	// Enforce size limit, if it was lowered
+	for s.config.MaxSize > 0 && s.size > s.config.MaxSize {
+		s.remove(s.order.Back(), EvictCapacity)
+	}
+
+	return nil
+}
+
+// diskExpires reads an entry file expiration header.
+func diskExpires(path string) (time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+
+	header := make([]byte, diskHeaderSize)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return time.Time{}, err
+	}
+	if nanos := binary.BigEndian.Uint64(header); nanos != 0 {
+		return time.Unix(0, int64(nanos)), nil
+	}
+
+	return time.Time{}, nil
+}
+
+/*
+NewDiskStore is a DiskStore builder.
+It creates a directory, if not exists, and indexes existing entries.
+Check DiskStore and DiskConfig for details.
+
+Usage:
+
+	store, err := cache.NewDiskStore[string, *Report](cache.DiskConfig{
+		Dir:     filepath.Join(os.TempDir(), "reports"),
+		Codec:   cache.GobCodec{},
+		TTL:     24 * time.Hour,
+		MaxSize: 100 << 20,
+	})
+	report, err := store.GetOrLoad("weekly", buildreport)
+*/
+func NewDiskStore[K comparable, V any](config DiskConfig) (*DiskStore[K, V], error) {
+	// Defaults
+	if config.Codec == nil {
+		config.Codec = JSONCodec{}
+	}
+	// Prepare directory
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &DiskStore[K, V]{
+		config:  config,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+		stats:   &counters{},
+	}
+	if err := s.scan(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
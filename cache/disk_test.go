@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiskStoreTimeKey(t *testing.T) {
+	store, err := NewDiskStore[time.Time, string](DiskConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decoded time differs from original (monotonic clock, location)
+	key := time.Now()
+	if err := store.Set(key, "value"); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := store.Get(key); !ok || value != "value" {
+		t.Fatalf("expected stored value, got %q, %v", value, ok)
+	}
+	if store.Len() != 1 {
+		t.Fatalf("expected entry to be kept, got %d entries", store.Len())
+	}
+}
+
+func TestDiskStoreConcurrent(t *testing.T) {
+	store, err := NewDiskStore[string, int](DiskConfig{Dir: t.TempDir(), MaxSize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("key%d", i%10)
+				if err := store.Set(key, g*100+i); err != nil {
+					t.Error(err)
+					return
+				}
+				store.Get(key)
+				if i%7 == 0 {
+					store.Delete(key) //nolint:errcheck
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if store.Size() > 2048 {
+		t.Fatalf("expected size to fit 2048, got %d", store.Size())
+	}
+	// Index matches files
+	reopened, err := NewDiskStore[string, int](DiskConfig{Dir: store.config.Dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != store.Len() || reopened.Size() != store.Size() {
+		t.Fatalf("expected reopened store to match, got %d/%d entries", reopened.Len(), store.Len())
+	}
+}
+
+func TestDiskStoreName(t *testing.T) {
+	type key struct {
+		ID   int
+		Kind string
+	}
+	dir := t.TempDir()
+	gob, err := NewDiskStore[key, string](DiskConfig{Dir: dir, Codec: GobCodec{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	json, err := NewDiskStore[key, string](DiskConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// File names don't depend on codec
+	for _, k := range []key{{1, "a"}, {2, "b"}} {
+		gobname, _ := gob.name(k)
+		jsonname, _ := json.name(k)
+		if gobname != jsonname {
+			t.Fatalf("expected codec-independent name for %v, got %s and %s", k, gobname, jsonname)
+		}
+	}
+	// Entries are found after reopening
+	if err := gob.Set(key{1, "a"}, "value"); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewDiskStore[key, string](DiskConfig{Dir: dir, Codec: GobCodec{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := reopened.Get(key{1, "a"}); !ok || value != "value" {
+		t.Fatalf("expected stored value after reopening, got %q, %v", value, ok)
+	}
+}
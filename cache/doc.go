@@ -1,4 +1,113 @@
 /*
 cache - provides caching utility functions.
+
+# Cache
+
+Cache is a generic in-memory key/value cache with per-entry TTL,
+capacity limits (by count or by cost), pluggable eviction policies
+(LRU, LFU, W-TinyLFU), eviction callbacks and sharding.
+It's the base for other cache helpers in the package.
+
+	users := cache.New(cache.Config[int, *User]{
+		TTL:        10 * time.Minute,
+		MaxEntries: 10000,
+		Policy:     cache.TinyLFU[int],
+	})
+	users.Set(42, user)
+	user, ok := users.Get(42)
+	user, err := users.GetOrLoad(42, loaduser)
+
+# Tags / dependencies
+
+Tagged is a Cache with invalidation by tags and by dependencies between entries.
+Invalidations might be broadcasted with an Invalidator
+(ChanInvalidator in-process, or your own over an external bus).
+
+	users := cache.NewTagged(cache.Config[string, any]{TTL: time.Minute})
+	users.SetWithTags("user:42:profile", profile, "user:42", "org:7")
+	users.Set("user:42:feed", feed)
+	users.Depend("user:42:feed", "user:42:profile")
+	users.InvalidateTag("org:7") // Drops profile and feed
+
+	invalidator := cache.NewChanInvalidator[string](16)
+	defer users.Listen(invalidator)()
+	invalidator.Publish(cache.Invalidation[string]{Tags: []string{"user:42"}})
+
+# Disk store
+
+DiskStore keeps values on disk across process restarts,
+with TTLs, size limit (least recently used entries are evicted) and atomic writes.
+Values are serialized with a Codec, JSONCodec and GobCodec are built-in.
+
+	store, err := cache.NewDiskStore[string, *Report](cache.DiskConfig{
+		Dir:     ".cache/reports",
+		Codec:   cache.GobCodec{},
+		TTL:     24 * time.Hour,
+		MaxSize: 100 << 20,
+	})
+	report, err := store.GetOrLoad("weekly", buildreport)
+
+# Snapshots / warm-up
+
+Stores might be dumped into a versioned snapshot and restored from it,
+keeping remaining TTLs, so a service starts with a warm cache after restart.
+Warm loads a list of keys with bounded concurrency.
+
+	count, err := cache.WriteSnapshot[int, *User](file, users, cache.GobCodec{})
+	count, err = cache.ReadSnapshot[int, *User](file, users, cache.GobCodec{})
+	count, err = cache.Warm[int, *User](users, popular, db.User, 8)
+
+# Cached functions
+
+CachedFunc caches a getter result, Memoize caches a function with arguments.
+Both are safe for concurrent use and de-duplicate loads.
+
+	getconfig := cache.NewCachedFunc(time.Minute, loadconfig, cache.WithErrorTTL(5*time.Second))
+	getuser := cache.Memoize(time.Minute, loaduser, cache.WithMaxEntries(1000))
+
+To avoid latency spikes on expiration, values might be refreshed in background.
+With stale-while-revalidate, expired value is served for a while, during a refresh.
+With refresh-ahead, a refresh starts before expiration.
+Cache.GetOrLoad supports the same with StaleTTL and RefreshAhead config fields.
+
+	getconfig := cache.NewCachedFunc(time.Minute, loadconfig, cache.WithStaleTTL(10*time.Minute))
+	getuser := cache.Memoize(time.Minute, loaduser, cache.WithRefreshAhead(0.8))
+
+# Statistics
+
+All cache types are reporting hits, misses, loads, load errors, load duration
+and evictions by reason with Stats method.
+Metrics registry collects statistics of multiple caches,
+to export them into any metrics system or in Prometheus text format.
+
+	metrics := cache.NewMetrics()
+	getconfig := cache.NewCachedFunc(time.Minute, loadconfig, cache.WithMetrics(metrics, "config"))
+	metrics.Register("users", users)
+	http.Handle("/metrics/cache", metrics)
+	log.Println(users.Stats().HitRatio())
+
+# Periodic functions
+
+PeriodicFunc refreshes a getter result in background,
+PeriodicPool manages multiple periodic functions.
+
+	getrates := cache.NewPeriodicFunc(ctx, time.Minute, loadrates)
+
+Periodic allows to wait for the first load, to stop refreshing,
+and to configure jitter, backoff on errors and update callback.
+On a failed refresh, the last good value is kept.
+
+	rates := cache.NewPeriodic(ctx, cache.PeriodicConfig[Rates]{Interval: time.Minute, Jitter: 0.1}, loadrates)
+	err := rates.Wait(ctx)
+	current, err := rates.Get()
+
+PeriodicPool keeps multiple functions under string keys,
+allowing to refresh, inspect and stop them separately.
+
+	pool := cache.NewPeriodicPool[Rates](ctx)
+	defer pool.Close()
+	pool.New("usd", time.Minute, loadusd)
+	pool.Refresh("usd")
+	status, err := pool.Status("usd")
 */
 package cache
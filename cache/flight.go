@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrLoadPanic = errors.New("load panicked")
+
+// flightCall is an in-flight (or completed) load.
+type flightCall[V any] struct {
+	done      chan struct{}
+	value     V
+	err       error
+	forgotten bool // detached with Forget, result is outdated
+}
+
+// flight de-duplicates concurrent loads per key,
+// so exactly one load is in flight while others wait for it.
+type flight[K comparable, V any] struct {
+	lock  sync.Mutex
+	calls map[K]*flightCall[V]
+}
+
+// Do executes fn once per key for concurrent callers.
+func (f *flight[K, V]) Do(key K, fn func() (V, error)) (V, error) {
+	return f.DoCall(key, func(*flightCall[V]) (V, error) {
+		return fn()
+	})
+}
+
+// DoCall is Do, which passes the call to fn (to check if it was forgotten).
+func (f *flight[K, V]) DoCall(key K, fn func(call *flightCall[V]) (V, error)) (V, error) {
+	f.lock.Lock()
+	if f.calls == nil {
+		f.calls = map[K]*flightCall[V]{}
+	}
+	// Wait for in-flight load
+	if call, ok := f.calls[key]; ok {
+		f.lock.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	// Start a new load
+	call := &flightCall[V]{done: make(chan struct{})}
+	f.calls[key] = call
+	f.lock.Unlock()
+	// Load, releasing waiters even on panic
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			var zero V
+			call.value, call.err = zero, fmt.Errorf("%w: %v", ErrLoadPanic, recovered)
+		}
+		f.lock.Lock()
+		if f.calls[key] == call {
+			delete(f.calls, key)
+		}
+		f.lock.Unlock()
+		close(call.done)
+		// Waiters got an error, the leader keeps panicking
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+	call.value, call.err = fn(call)
+
+	return call.value, call.err
+}
+
+// Forget detaches in-flight calls for given keys,
+// so the next callers start new loads instead of joining outdated ones.
+func (f *flight[K, V]) Forget(keys ...K) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, key := range keys {
+		if call, ok := f.calls[key]; ok {
+			call.forgotten = true
+			delete(f.calls, key)
+		}
+	}
+}
+
+// ForgetAll detaches all in-flight calls.
+func (f *flight[K, V]) ForgetAll() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for key, call := range f.calls {
+		call.forgotten = true
+		delete(f.calls, key)
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightDeduplicates(t *testing.T) {
+	var (
+		f       flight[string, int]
+		calls   int32
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	results := make([]int, 10)
+	for i := range results {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = f.Do("key", func() (int, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return 42, nil
+			})
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+	for _, result := range results {
+		if result != 42 {
+			t.Fatalf("expected 42, got %d", result)
+		}
+	}
+}
+
+func TestFlightPanic(t *testing.T) {
+	var (
+		f       flight[string, int]
+		started = make(chan struct{})
+		release = make(chan struct{})
+		waited  = make(chan error)
+	)
+	go func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected leader to panic")
+			}
+		}()
+		f.Do("key", func() (int, error) { //nolint:errcheck
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+	go func() {
+		_, err := f.Do("key", func() (int, error) { return 1, nil })
+		waited <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if err := <-waited; !errors.Is(err, ErrLoadPanic) {
+		t.Fatalf("expected ErrLoadPanic, got %v", err)
+	}
+	// Next load is not affected
+	if value, err := f.Do("key", func() (int, error) { return 2, nil }); value != 2 || err != nil {
+		t.Fatalf("unexpected result %d, %v", value, err)
+	}
+}
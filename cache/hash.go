@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+)
+
+// hashSeed is a process-wide seed for key hashing.
+var hashSeed = maphash.MakeSeed()
+
+// hashKey hashes a comparable key, with fast paths for common key types.
+func hashKey[K comparable](key K) uint64 {
+	var (
+		h   maphash.Hash
+		buf [8]byte
+	)
+	h.SetSeed(hashSeed)
+	switch k := any(key).(type) {
+	case string:
+		h.WriteString(k) //nolint:errcheck
+	case int:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:]) //nolint:errcheck
+	case int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:]) //nolint:errcheck
+	case uint64:
+		binary.LittleEndian.PutUint64(buf[:], k)
+		h.Write(buf[:]) //nolint:errcheck
+	case int32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:]) //nolint:errcheck
+	case uint32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:]) //nolint:errcheck
+	default:
+		// Equal keys have equal representation, which is enough for hashing
+		fmt.Fprintf(&h, "%#v", key)
+	}
+
+	return h.Sum64()
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrInvalidatorClosed = errors.New("invalidator is closed")
+
+/*
+Invalidation is an invalidation message,
+which drops entries by keys and by tags.
+*/
+type Invalidation[K comparable] struct {
+	Keys []K      `json:"keys,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+/*
+Invalidator delivers invalidations to subscribed caches (see Tagged.Listen).
+It might be implemented over an external bus (Redis pub/sub, NATS, etc),
+to keep caches of multiple processes consistent.
+ChanInvalidator is a built-in in-process implementation.
+*/
+type Invalidator[K comparable] interface {
+	// Publish delivers an invalidation to all subscribers
+	Publish(msg Invalidation[K]) error
+	// Subscribe calls fn for each published invalidation,
+	// until returned function is called
+	Subscribe(fn func(msg Invalidation[K])) func()
+}
+
+// chanSubscriber is a ChanInvalidator subscriber.
+type chanSubscriber[K comparable] struct {
+	messages chan Invalidation[K]
+	done     chan struct{}
+	once     sync.Once
+}
+
+// stop stops the subscriber.
+func (s *chanSubscriber[K]) stop() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+/*
+ChanInvalidator is an in-process Invalidator, built on channels.
+Each subscriber has own buffered channel and goroutine,
+so a slow subscriber doesn't block the others until its buffer is full.
+Use NewChanInvalidator to create it.
+
+Usage:
+
+	invalidator := cache.NewChanInvalidator[string](16)
+	defer invalidator.Close()
+	users := cache.NewTagged[string, *User]()
+	defer users.Listen(invalidator)()
+	invalidator.Publish(cache.Invalidation[string]{Tags: []string{"org:7"}})
+*/
+type ChanInvalidator[K comparable] struct {
+	buffer      int
+	lock        sync.RWMutex
+	subscribers map[*chanSubscriber[K]]struct{}
+	closed      bool
+}
+
+/*
+Publish sends an invalidation to all subscribers.
+It waits only for subscribers with full buffers.
+*/
+func (i *ChanInvalidator[K]) Publish(msg Invalidation[K]) error {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if i.closed {
+		return ErrInvalidatorClosed
+	}
+	for subscriber := range i.subscribers {
+		select {
+		case subscriber.messages <- msg:
+		case <-subscriber.done:
+		}
+	}
+
+	return nil
+}
+
+/*
+Subscribe calls fn for each published invalidation in a separate goroutine,
+until returned function is called or invalidator is closed.
+*/
+func (i *ChanInvalidator[K]) Subscribe(fn func(msg Invalidation[K])) func() {
+	subscriber := &chanSubscriber[K]{
+		messages: make(chan Invalidation[K], i.buffer),
+		done:     make(chan struct{}),
+	}
+	i.lock.Lock()
+	if i.closed {
+		i.lock.Unlock()
+		return func() {}
+	}
+	i.subscribers[subscriber] = struct{}{}
+	i.lock.Unlock()
+	// Deliver
+	go func() {
+		for {
+			select {
+			case <-subscriber.done:
+				return
+			case msg := <-subscriber.messages:
+				fn(msg)
+			}
+		}
+	}()
+	// Unsubscribe, without waiting for publishers
+	return func() {
+		subscriber.stop()
+		i.lock.Lock()
+		delete(i.subscribers, subscriber)
+		i.lock.Unlock()
+	}
+}
+
+/*
+Close stops all subscribers.
+Publish returns ErrInvalidatorClosed after that.
+*/
+func (i *ChanInvalidator[K]) Close() error {
+	// Stop subscribers first, to release blocked publishers
+	i.lock.RLock()
+	for subscriber := range i.subscribers {
+		subscriber.stop()
+	}
+	i.lock.RUnlock()
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.closed = true
+	for subscriber := range i.subscribers {
+		subscriber.stop()
+	}
+	i.subscribers = map[*chanSubscriber[K]]struct{}{}
+
+	return nil
+}
+
+/*
+NewChanInvalidator is a ChanInvalidator constructor.
+Buffer is a per-subscriber channel size.
+*/
+func NewChanInvalidator[K comparable](buffer int) *ChanInvalidator[K] {
+	return &ChanInvalidator[K]{
+		buffer:      buffer,
+		subscribers: map[*chanSubscriber[K]]struct{}{},
+	}
+}
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+Memo is a keyed cached getter, which is used under the hood of Memoize.
+Unlike Memoize, it allows to invalidate cached keys.
+Loads are de-duplicated per key, so there is at most one load in flight for a key.
+Results are stored in a Cache with LRU eviction.
+Stale-while-revalidate and refresh-ahead loading are enabled with
+WithStaleTTL and WithRefreshAhead options.
+Use NewMemo to create it.
+*/
+type Memo[K comparable, V any] struct {
+	fn     func(K) (V, error)
+	expire time.Duration
+	opts   options
+	cache  *Cache[K, memoResult[V]]
+	flight flight[K, V]
+
+	// lock orders stores with invalidations
+	lock sync.Mutex
+}
+
+// memoResult is a cached load result.
+type memoResult[V any] struct {
+	value V
+	err   error
+}
+
+/*
+Get returns a cached value for a key, or loads a new one if missing or expired.
+*/
+func (m *Memo[K, V]) Get(key K) (V, error) {
+	if result, refresh, ok := m.cache.lookup(key); ok {
+		// Stale or about to expire, load in background
+		if refresh {
+			go m.load(key, true) //nolint:errcheck
+		}
+		return result.value, result.err
+	}
+
+	return m.load(key, false)
+}
+
+// load loads a value, once per key for concurrent callers.
+// Background load errors are not stored, to keep serving the stale value.
+func (m *Memo[K, V]) load(key K, background bool) (V, error) {
+	return m.flight.DoCall(key, func(call *flightCall[V]) (V, error) {
+		// Result might be stored by a load, finished in between
+		if !background {
+			if result, ok := m.cache.peek(key); ok {
+				return result.value, result.err
+			}
+		}
+		start := time.Now()
+		value, err := m.fn(key)
+		m.cache.stats.load(start, err)
+		if background && err != nil {
+			m.cache.unmark(key)
+			return value, err
+		}
+		m.store(key, call, value, err)
+		return value, err
+	})
+}
+
+// store saves a loaded value.
+// Loads, which were in flight during the key invalidation, are not stored.
+func (m *Memo[K, V]) store(key K, call *flightCall[V], value V, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if call.forgotten {
+		return
+	}
+	expire := m.expire
+	if err != nil {
+		expire = m.opts.errorExpire(m.expire)
+	}
+	if expire > 0 {
+		m.cache.SetWithTTL(key, memoResult[V]{value, err}, expire)
+	}
+}
+
+/*
+Invalidate drops cached values for given keys.
+Loads of these keys, which are in flight, are not cached,
+and the next Get starts a new load instead of waiting for them.
+Other keys are not affected.
+*/
+func (m *Memo[K, V]) Invalidate(keys ...K) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, key := range keys {
+		m.cache.Delete(key)
+	}
+	m.flight.Forget(keys...)
+}
+
+/*
+InvalidateAll drops all cached values.
+*/
+func (m *Memo[K, V]) InvalidateAll() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.cache.Clear()
+	m.flight.ForgetAll()
+}
+
+/*
+Dump calls fn for each cached successful result with its expiration time,
+until fn returns false.
+Check WriteSnapshot for details.
+*/
+func (m *Memo[K, V]) Dump(fn func(key K, value V, expires time.Time) bool) {
+	m.cache.Dump(func(key K, result memoResult[V], expires time.Time) bool {
+		if result.err != nil {
+			return true
+		}
+		return fn(key, result.value, expires)
+	})
+}
+
+/*
+Restore stores a result with a given expiration time.
+Check ReadSnapshot for details.
+*/
+func (m *Memo[K, V]) Restore(key K, value V, expires time.Time) error {
+	return m.cache.Restore(key, memoResult[V]{value: value}, expires)
+}
+
+/*
+Stats returns memo statistics.
+*/
+func (m *Memo[K, V]) Stats() Stats {
+	return m.cache.Stats()
+}
+
+/*
+Len returns a number of cached keys (including expired, but not yet evicted).
+*/
+func (m *Memo[K, V]) Len() int {
+	return m.cache.Len()
+}
+
+/*
+NewMemo is a Memo builder.
+Check Memo and Memoize for details.
+
+Usage:
+
+	memo := cache.NewMemo(1 * time.Minute, func(id int) (*User, error) {
+		return db.User(id)
+	}, cache.WithMaxEntries(1000))
+	user, err := memo.Get(42)
+	memo.Invalidate(42)
+*/
+func NewMemo[K comparable, V any](expire time.Duration, fn func(K) (V, error), opts ...Option) *Memo[K, V] {
+	o := newOptions(opts)
+	m := &Memo[K, V]{
+		fn:     fn,
+		expire: expire,
+		opts:   o,
+		cache: New(Config[K, memoResult[V]]{
+			MaxEntries:   o.maxEntries,
+			StaleTTL:     o.staleTTL,
+			RefreshAhead: o.refresh,
+		}),
+	}
+	o.register(m)
+
+	return m
+}
+
+/*
+Memoize wraps a function with arguments with a keyed cache.
+Loads are de-duplicated per key,
+number of cached keys might be limited with WithMaxEntries
+(least recently used keys are evicted).
+Use NewMemo if you need to invalidate cached keys.
+
+Usage:
+
+	getuser := cache.Memoize(1 * time.Minute, func(id int) (*User, error) {
+		return db.User(id)
+	}, cache.WithMaxEntries(1000))
+	user, err := getuser(42) // Loads
+	user, err = getuser(42) // Gets a value from cache
+*/
+func Memoize[K comparable, V any](ttl time.Duration, fn func(K) (V, error), opts ...Option) func(K) (V, error) {
+	return NewMemo(ttl, fn, opts...).Get
+}
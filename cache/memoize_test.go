@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoInvalidateKeepsOtherKeys(t *testing.T) {
+	release := make(chan struct{})
+	memo := NewMemo(time.Minute, func(key string) (int, error) {
+		if key == "slow" {
+			<-release
+		}
+		return len(key), nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		memo.Get("slow") //nolint:errcheck
+	}()
+	time.Sleep(20 * time.Millisecond)
+	// Invalidating another key must not drop the in-flight load
+	memo.Invalidate("other")
+	close(release)
+	<-done
+	if memo.Len() != 1 {
+		t.Fatalf("expected slow key to be cached, got %d entries", memo.Len())
+	}
+}
+
+func TestMemoInvalidateBypassesInFlight(t *testing.T) {
+	var (
+		calls   int32
+		release = make(chan struct{})
+	)
+	memo := NewMemo(time.Minute, func(key string) (int, error) {
+		// First load is blocked and returns an outdated value
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-release
+			return 1, nil
+		}
+		return 2, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		memo.Get("key") //nolint:errcheck
+	}()
+	time.Sleep(20 * time.Millisecond)
+	memo.Invalidate("key")
+	// New caller must not join the outdated load
+	if value, _ := memo.Get("key"); value != 2 {
+		t.Fatalf("expected fresh value 2, got %d", value)
+	}
+	close(release)
+	<-done
+	// Outdated load must not overwrite the fresh value
+	if value, _ := memo.Get("key"); value != 2 {
+		t.Fatalf("expected cached value 2, got %d", value)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yznts/zen/v3/internal/promtext"
+)
+
+/*
+Metrics is a registry of named caches, which exports their statistics.
+Use Snapshot to export statistics into any metrics system,
+or WriteTo/ServeHTTP for Prometheus text format.
+Helpers (Memoize, CachedFunc, PeriodicFunc, etc) are registered with WithMetrics option,
+other caches with Register.
+It's safe for concurrent use.
+
+Usage:
+
+	metrics := cache.NewMetrics()
+	getconfig := cache.NewCachedFunc(time.Minute, loadconfig, cache.WithMetrics(metrics, "config"))
+	metrics.Register("users", users)
+	http.Handle("/metrics/cache", metrics)
+*/
+type Metrics struct {
+	// Namespace is a metric names prefix. Defaults to "cache"
+	Namespace string
+
+	lock    sync.RWMutex
+	sources map[string]StatsSource
+}
+
+/*
+Register adds a cache under given name, replacing existing one.
+*/
+func (m *Metrics) Register(name string, source StatsSource) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.sources == nil {
+		m.sources = map[string]StatsSource{}
+	}
+	m.sources[name] = source
+}
+
+/*
+Unregister removes a cache with given name.
+*/
+func (m *Metrics) Unregister(name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.sources, name)
+}
+
+// unregister removes a cache with given name, if it wasn't replaced.
+func (m *Metrics) unregister(name string, source StatsSource) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.sources[name] == source {
+		delete(m.sources, name)
+	}
+}
+
+/*
+Snapshot returns statistics of all registered caches by name.
+*/
+func (m *Metrics) Snapshot() map[string]Stats {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	snapshot := make(map[string]Stats, len(m.sources))
+	for name, source := range m.sources {
+		snapshot[name] = source.Stats()
+	}
+
+	return snapshot
+}
+
+/*
+WriteTo writes metrics in Prometheus text format.
+*/
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var (
+		snapshot  = m.Snapshot()
+		names     = make([]string, 0, len(snapshot))
+		namespace = m.Namespace
+		out       = &strings.Builder{}
+	)
+	if namespace == "" {
+		namespace = "cache"
+	}
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// Counters and gauges
+	metrics := []struct {
+		name, kind, help string
+		value            func(Stats) string
+	}{
+		{"hits_total", "counter", "Total number of cache hits.", func(s Stats) string { return strconv.FormatUint(s.Hits, 10) }},
+		{"misses_total", "counter", "Total number of cache misses.", func(s Stats) string { return strconv.FormatUint(s.Misses, 10) }},
+		{"hit_ratio", "gauge", "Cache hits share of all lookups.", func(s Stats) string { return promtext.Float(s.HitRatio()) }},
+		{"loads_total", "counter", "Total number of loads.", func(s Stats) string { return strconv.FormatUint(s.Loads, 10) }},
+		{"load_errors_total", "counter", "Total number of failed loads.", func(s Stats) string { return strconv.FormatUint(s.LoadErrors, 10) }},
+		{"load_duration_seconds_total", "counter", "Total loads duration.", func(s Stats) string { return promtext.Float(s.LoadTime.Seconds()) }},
+	}
+	for _, metric := range metrics {
+		promtext.Header(out, namespace+"_"+metric.name, metric.kind, metric.help)
+		for _, name := range names {
+			promtext.Sample(out, namespace+"_"+metric.name, "cache="+promtext.Label(name), metric.value(snapshot[name]))
+		}
+	}
+	// Evictions
+	metric := namespace + "_evictions_total"
+	promtext.Header(out, metric, "counter", "Total number of removed entries by reason.")
+	for _, name := range names {
+		for reason := EvictReason(0); reason < evictReasons; reason++ {
+			labels := "cache=" + promtext.Label(name) + ",reason=" + promtext.Label(reason.String())
+			promtext.Sample(out, metric, labels, strconv.FormatUint(snapshot[name].Evictions[reason], 10))
+		}
+	}
+	// Write
+	n, err := io.WriteString(w, out.String())
+
+	return int64(n), err
+}
+
+/*
+ServeHTTP exports metrics in Prometheus text format.
+*/
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	promtext.Serve(w, m)
+}
+
+/*
+NewMetrics is a Metrics constructor.
+*/
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Namespace: "cache",
+		sources:   map[string]StatsSource{},
+	}
+}
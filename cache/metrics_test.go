@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMetricsPeriodicUnregister(t *testing.T) {
+	metrics := NewMetrics()
+	ctx, cancel := context.WithCancel(context.Background())
+	NewPeriodicFunc(ctx, time.Minute, func() (int, error) {
+		return 1, nil
+	}, WithMetrics(metrics, "rates"))
+	if _, ok := metrics.Snapshot()["rates"]; !ok {
+		t.Fatalf("expected periodic to be registered")
+	}
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for len(metrics.Snapshot()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(metrics.Snapshot()) > 0 {
+		t.Fatalf("expected periodic to be unregistered after stop")
+	}
+}
+
+func TestMetricsPeriodicPoolUnregister(t *testing.T) {
+	metrics := NewMetrics()
+	pool := NewPeriodicPool[int](context.Background(), WithMetrics(metrics, "pool"))
+	fn := func() (int, error) { return 1, nil }
+	pool.New("a", time.Minute, fn)
+	pool.New("b", time.Minute, fn)
+	// Replaced function must not unregister the new one
+	pool.New("a", time.Minute, fn)
+	if snapshot := metrics.Snapshot(); len(snapshot) != 2 {
+		t.Fatalf("expected 2 registered functions, got %v", snapshot)
+	}
+	pool.Remove("b") //nolint:errcheck
+	if _, ok := metrics.Snapshot()["pool:b"]; ok {
+		t.Fatalf("expected removed function to be unregistered")
+	}
+	if _, ok := metrics.Snapshot()["pool:a"]; !ok {
+		t.Fatalf("expected remaining function to stay registered")
+	}
+	pool.Close() //nolint:errcheck
+	if len(metrics.Snapshot()) > 0 {
+		t.Fatalf("expected all functions to be unregistered after Close")
+	}
+}
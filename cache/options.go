@@ -0,0 +1,103 @@
+package cache
+
+import "time"
+
+/*
+Option configures cache helpers (CachedFunc, Memoize, etc).
+Options, which are not relevant for a helper, are ignored.
+*/
+type Option func(*options)
+
+// options holds helpers configuration.
+type options struct {
+	errorTTL    time.Duration
+	errorTTLSet bool
+	maxEntries  int
+	staleTTL    time.Duration
+	refresh     float64
+	metrics     *Metrics
+	name        string
+}
+
+// newOptions applies options.
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// register adds a helper to metrics registry, if configured.
+func (o options) register(source StatsSource) {
+	if o.metrics != nil {
+		o.metrics.Register(o.name, source)
+	}
+}
+
+// errorExpire returns error cache duration, falling back to value ttl.
+func (o options) errorExpire(ttl time.Duration) time.Duration {
+	if o.errorTTLSet {
+		return o.errorTTL
+	}
+
+	return ttl
+}
+
+/*
+WithErrorTTL sets a duration to cache errors for (negative caching).
+By default, errors are cached for the same duration as values.
+Zero duration disables errors caching,
+so the next call will load again.
+*/
+func WithErrorTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.errorTTL = ttl
+		o.errorTTLSet = true
+	}
+}
+
+/*
+WithMaxEntries limits a number of cached keys for keyed helpers (like Memoize).
+Zero means no limit.
+*/
+func WithMaxEntries(max int) Option {
+	return func(o *options) {
+		o.maxEntries = max
+	}
+}
+
+/*
+WithStaleTTL enables stale-while-revalidate mode.
+After TTL (soft expiration) the stale value is still returned immediately,
+while a new one is loaded in background.
+Stale value is dropped after TTL + stale duration (hard expiration).
+Failed background loads are keeping the stale value.
+*/
+func WithStaleTTL(stale time.Duration) Option {
+	return func(o *options) {
+		o.staleTTL = stale
+	}
+}
+
+/*
+WithRefreshAhead starts a background load when a given fraction (0..1) of TTL is passed,
+so the value is refreshed before expiration and callers don't wait for it.
+F.e. 0.8 with 1 minute TTL starts a load after 48 seconds.
+*/
+func WithRefreshAhead(fraction float64) Option {
+	return func(o *options) {
+		o.refresh = fraction
+	}
+}
+
+/*
+WithMetrics registers a helper statistics in a Metrics registry under given name.
+*/
+func WithMetrics(metrics *Metrics, name string) Option {
+	return func(o *options) {
+		o.metrics = metrics
+		o.name = name
+	}
+}
@@ -2,54 +2,379 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"math/rand"
+	"sync"
 	"time"
 )
 
+var ErrPeriodicNotReady = errors.New("periodic function is not loaded yet")
+
+// PeriodicMaxJitter is an upper limit of PeriodicConfig.Jitter.
+var PeriodicMaxJitter = 0.9
+
 /*
 PeriodicFunc is a function wrapper that periodically executes function and caches result.
-As far as CachedFunc doesn't support functions with arguments
-(it will require much more effort and complications),
-you'll need to create periodic getter for each argument set.
-At least, until the creation of more advanced periodic builder.
+Periodic getter is created for each argument set,
+use PeriodicPool to manage multiple getters,
+or Memoize for on-demand keyed caching.
 */
 type PeriodicFunc[T any] func() (T, error)
 
 /*
 NewPeriodicFunc is a PeriodicFunc builder.
 Check PeriodicFunc for details.
+Use NewPeriodic if you need to wait for the first load,
+or to configure jitter, backoff, etc.
+Options, except WithMetrics, are ignored.
+Metrics registration is removed when the context is done.
+Interval must be positive.
+
+Getter returns ErrPeriodicNotReady until the first load is finished
+(earlier versions were returning a zero value without error),
+and the first load error until the first successful load.
 
 Usage:
 
 	getter := NewPeriodicFunc(context.Background(), 1 * time.Minute, func() (string, error) {
-		time.Sleep(1 * time.Second) Imitate some work
-		return "Function cached result"
+		time.Sleep(1 * time.Second) // Imitate some work
+		return "Function cached result", nil
 	})
 	time.Sleep(1 * time.Second)
 	log.Println(getter()) // Get a value from cache
 	log.Println(getter()) // Get a value from cache
 */
-func NewPeriodicFunc[T any](ctx context.Context, interval time.Duration, fn PeriodicFunc[T]) PeriodicFunc[T] {
-	// Define variables
-	var (
-		err   error
-		value T
-	)
-	// Schedule periodic update gorutine
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				// Update cache
-				value, err = fn()
-				// Sleep for interval
-				time.Sleep(interval)
-			}
+func NewPeriodicFunc[T any](ctx context.Context, interval time.Duration, fn PeriodicFunc[T], opts ...Option) PeriodicFunc[T] {
+	o := newOptions(opts)
+	p := NewPeriodic(ctx, PeriodicConfig[T]{Interval: interval}, fn)
+	p.register(o.metrics, o.name)
+
+	return p.Get
+}
+
+/*
+PeriodicConfig is a Periodic configuration.
+Only Interval is required.
+*/
+type PeriodicConfig[T any] struct {
+	// Interval between loads, counted from the load start.
+	// Must be positive
+	Interval time.Duration
+	// Jitter is a random interval deviation fraction [0..1),
+	// f.e. 0.1 with 1 minute interval gives 54-66 seconds.
+	// Negative values are treated as 0, values from 1 are clamped to PeriodicMaxJitter
+	Jitter float64
+	// Backoff is a retry delay after the first failed load,
+	// doubled after each next failure (Interval/8 by default)
+	Backoff time.Duration
+	// MaxBackoff limits retry delay (Interval by default)
+	MaxBackoff time.Duration
+	// OnUpdate is called after each load.
+	// On failure, it's called with the last good value and the error
+	OnUpdate func(value T, err error)
+}
+
+/*
+Periodic is a periodically refreshed value holder, which is used under the hood of PeriodicFunc.
+Value is loaded in background right after creation,
+and then refreshed each interval until the context is done or Stop is called.
+If a refresh fails, the last good value is kept and the load is retried
+with exponential backoff.
+It's safe for concurrent use.
+Use NewPeriodic to create it.
+*/
+type Periodic[T any] struct {
+	config  PeriodicConfig[T]
+	fn      func() (T, error)
+	cancel  context.CancelFunc
+	ready   chan struct{}
+	done    chan struct{}
+	refresh chan struct{}
+
+	lock     sync.RWMutex
+	value    T
+	err      error
+	loaded   bool
+	attempts int
+	failures int
+	status   PeriodicStatus
+	stats    *counters
+	stopped  bool
+	metrics  *Metrics // registry to leave on stop
+	name     string
+}
+
+/*
+PeriodicStatus is a Periodic state snapshot.
+*/
+type PeriodicStatus struct {
+	// LastSuccess is a time of the last successful load
+	LastSuccess time.Time
+	// LastError is the last load error, nil if the last load was successful
+	LastError error
+	// LastErrorAt is a time of the last failed load
+	LastErrorAt time.Time
+	// NextRun is a time of the next scheduled load, zero if stopped
+	NextRun time.Time
+}
+
+/*
+Get returns the last good value.
+Before the first successful load, it returns the load error,
+or ErrPeriodicNotReady if there were no loads yet.
+Use Err to check the last refresh error.
+*/
+func (p *Periodic[T]) Get() (T, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	p.stats.hit(p.loaded)
+	if !p.loaded && p.err != nil {
+		return p.value, p.err
+	}
+	if !p.loaded {
+		return p.value, ErrPeriodicNotReady
+	}
+
+	return p.value, nil
+}
+
+/*
+Err returns the last load error, or nil if the last load was successful.
+*/
+func (p *Periodic[T]) Err() error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.err
+}
+
+/*
+Ready returns a channel, which is closed after the first load
+(successful or not), or when periodic is stopped.
+*/
+func (p *Periodic[T]) Ready() <-chan struct{} {
+	return p.ready
+}
+
+/*
+Wait waits for the first load and returns its error.
+Returns context error if context is done earlier.
+*/
+func (p *Periodic[T]) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.ready:
+	}
+	_, err := p.Get()
+
+	return err
+}
+
+/*
+Status returns the current periodic state.
+*/
+func (p *Periodic[T]) Status() PeriodicStatus {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.status
+}
+
+/*
+Stats returns periodic statistics.
+Get calls before the first successful load are counted as misses.
+*/
+func (p *Periodic[T]) Stats() Stats {
+	return p.stats.snapshot()
+}
+
+/*
+Refresh requests an immediate load, without waiting for it.
+The regular schedule continues from that load.
+Requests during a running load are coalesced into one more load.
+*/
+func (p *Periodic[T]) Refresh() {
+	select {
+	case p.refresh <- struct{}{}:
+	default:
+	}
+}
+
+/*
+Stop stops periodic refreshing and waits for a running load to finish.
+The last value is still available with Get,
+but periodic is removed from metrics registry.
+*/
+func (p *Periodic[T]) Stop() {
+	p.cancel()
+	<-p.done
+}
+
+// run is a scheduling loop.
+func (p *Periodic[T]) run(ctx context.Context) {
+	defer close(p.done)
+	// Ensure waiters are released, even if there were no loads
+	defer func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		if p.attempts == 0 {
+			close(p.ready)
+		}
+		p.status.NextRun = time.Time{}
+		p.stopped = true
+		if p.metrics != nil {
+			p.metrics.unregister(p.name, p)
 		}
 	}()
-	// Return getter
-	return func() (T, error) {
-		return value, err
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-p.refresh:
+			// Drain fired timer before reset
+			if !timer.Stop() {
+				<-timer.C
+			}
+		}
+		start := time.Now()
+		p.load()
+		// Schedule the next load from the load start,
+		// so the interval doesn't drift with load duration
+		delay := p.delay() - time.Since(start)
+		if delay < 0 {
+			delay = 0
+		}
+		timer.Reset(delay)
+		p.lock.Lock()
+		p.status.NextRun = time.Now().Add(delay)
+		p.lock.Unlock()
+	}
+}
+
+// register adds periodic to metrics registry, until it's stopped.
+func (p *Periodic[T]) register(metrics *Metrics, name string) {
+	if metrics == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.stopped {
+		return
+	}
+	p.metrics, p.name = metrics, name
+	metrics.Register(name, p)
+}
+
+// load executes the function and stores its result.
+func (p *Periodic[T]) load() {
+	start := time.Now()
+	value, err := p.fn()
+	p.stats.load(start, err)
+	p.lock.Lock()
+	// Keep the last good value on failure
+	if err == nil {
+		p.value, p.loaded, p.failures = value, true, 0
+		p.status.LastSuccess = time.Now()
+	} else {
+		p.failures++
+		p.status.LastErrorAt = time.Now()
+	}
+	p.err = err
+	p.status.LastError = err
+	if p.attempts++; p.attempts == 1 {
+		close(p.ready)
 	}
+	value = p.value
+	p.lock.Unlock()
+	// Notify
+	if p.config.OnUpdate != nil {
+		p.config.OnUpdate(value, err)
+	}
+}
+
+// delay returns a delay until the next load, with backoff and jitter applied.
+func (p *Periodic[T]) delay() time.Duration {
+	p.lock.RLock()
+	failures := p.failures
+	p.lock.RUnlock()
+	// Regular interval or exponential backoff
+	delay := p.config.Interval
+	if failures > 0 {
+		delay = p.config.Backoff
+		for i := 1; i < failures && delay < p.config.MaxBackoff; i++ {
+			delay *= 2
+		}
+		if delay > p.config.MaxBackoff {
+			delay = p.config.MaxBackoff
+		}
+	}
+	// Jitter
+	if p.config.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.config.Jitter * float64(delay)) //nolint:gosec
+	}
+
+	return delay
+}
+
+/*
+NewPeriodic is a Periodic builder.
+Check Periodic and PeriodicConfig for details.
+It panics if Interval is not positive.
+
+Usage:
+
+	rates := cache.NewPeriodic(ctx, cache.PeriodicConfig[Rates]{
+		Interval: 1 * time.Minute,
+		Jitter:   0.1,
+		OnUpdate: func(rates Rates, err error) {
+			if err != nil {
+				log.Println("rates refresh failed:", err)
+			}
+		},
+	}, loadrates)
+	defer rates.Stop()
+	if err := rates.Wait(ctx); err != nil {
+		log.Println("rates are not available yet:", err)
+	}
+	current, err := rates.Get()
+*/
+func NewPeriodic[T any](ctx context.Context, config PeriodicConfig[T], fn func() (T, error)) *Periodic[T] {
+	// Validate
+	if config.Interval <= 0 {
+		panic("NewPeriodic() requires a positive interval")
+	}
+	// Defaults
+	if config.Backoff <= 0 {
+		config.Backoff = config.Interval / 8
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = config.Interval
+	}
+	// Keep jittered delay positive
+	if config.Jitter < 0 {
+		config.Jitter = 0
+	}
+	if config.Jitter >= 1 {
+		config.Jitter = PeriodicMaxJitter
+	}
+	// Start
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Periodic[T]{
+		config:  config,
+		fn:      fn,
+		cancel:  cancel,
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+		refresh: make(chan struct{}, 1),
+		stats:   &counters{},
+	}
+	go p.run(ctx)
+
+	return p
 }
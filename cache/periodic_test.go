@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPeriodicWait(t *testing.T) {
+	release := make(chan struct{})
+	p := NewPeriodic(context.Background(), PeriodicConfig[int]{Interval: time.Minute}, func() (int, error) {
+		<-release
+		return 42, nil
+	})
+	defer p.Stop()
+	if _, err := p.Get(); !errors.Is(err, ErrPeriodicNotReady) {
+		t.Fatalf("expected ErrPeriodicNotReady, got %v", err)
+	}
+	close(release)
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if value, err := p.Get(); value != 42 || err != nil {
+		t.Fatalf("expected 42, got %d, %v", value, err)
+	}
+}
+
+func TestPeriodicRefresh(t *testing.T) {
+	var calls int32
+	p := NewPeriodic(context.Background(), PeriodicConfig[int32]{Interval: time.Hour}, func() (int32, error) {
+		return atomic.AddInt32(&calls, 1), nil
+	})
+	defer p.Stop()
+	p.Wait(context.Background()) //nolint:errcheck
+	p.Refresh()
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&calls) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	p.Stop()
+	if value, _ := p.Get(); value != 2 {
+		t.Fatalf("expected refreshed value 2, got %d", value)
+	}
+}
+
+func TestPeriodicStop(t *testing.T) {
+	var calls int32
+	p := NewPeriodic(context.Background(), PeriodicConfig[int]{Interval: 5 * time.Millisecond}, func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 1, nil
+	})
+	p.Wait(context.Background()) //nolint:errcheck
+	p.Stop()
+	stopped := atomic.LoadInt32(&calls)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != stopped {
+		t.Fatalf("expected no loads after Stop")
+	}
+	if !p.Status().NextRun.IsZero() {
+		t.Fatalf("expected no next run after Stop")
+	}
+	if value, err := p.Get(); value != 1 || err != nil {
+		t.Fatalf("expected the last value after Stop, got %d, %v", value, err)
+	}
+}
+
+func TestPeriodicRequiresInterval(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on zero interval")
+		}
+	}()
+	NewPeriodic(context.Background(), PeriodicConfig[int]{}, func() (int, error) {
+		return 0, nil
+	})
+}
+
+func TestPeriodicJitter(t *testing.T) {
+	for jitter, expected := range map[float64]float64{-1: 0, 0.5: 0.5, 1: PeriodicMaxJitter, 3: PeriodicMaxJitter} {
+		p := NewPeriodic(context.Background(), PeriodicConfig[int]{Interval: time.Minute, Jitter: jitter}, func() (int, error) {
+			return 0, nil
+		})
+		p.Stop()
+		if p.config.Jitter != expected {
+			t.Fatalf("jitter %v: expected %v, got %v", jitter, expected, p.config.Jitter)
+		}
+		for i := 0; i < 100; i++ {
+			if delay := p.delay(); delay <= 0 {
+				t.Fatalf("jitter %v: expected positive delay, got %s", jitter, delay)
+			}
+		}
+	}
+}
@@ -3,55 +3,221 @@ package cache
 import (
 	"context"
 	"errors"
+	"sort"
+	"sync"
 	"time"
 )
 
-var ErrPeriodicPoolMissingKey = errors.New("referenced key is missing")
+var (
+	ErrPeriodicPoolMissingKey = errors.New("referenced key is missing")
+	ErrPeriodicPoolClosed     = errors.New("periodic pool is closed")
+)
 
 /*
-PeriodicPool manages multiple PeriodicFunc.
-Main idea of pool is having shared context between
-PeriodicFunc in the pool.
+PeriodicPool manages multiple Periodic functions under string keys.
+All functions are sharing the pool context,
+and might be stopped separately with Remove, or all together with Close.
+With WithMetrics option, each function is registered as "<name>:<key>",
+until it's removed or the pool is closed.
+It's safe for concurrent use.
 */
 type PeriodicPool[T any] struct {
-	Pool    map[string]PeriodicFunc[T]
-	Context context.Context //nolint:containedctx
+	ctx     context.Context //nolint:containedctx
+	opts    options
+	lock    sync.RWMutex
+	entries map[string]*Periodic[T]
+	closed  bool
 }
 
-// New creates a new PeriodicFunc under given key.
+/*
+New creates a new PeriodicFunc under given key.
+Existing function under the same key is stopped and replaced.
+It's ignored after Close.
+*/
 func (p *PeriodicPool[T]) New(key string, interval time.Duration, fn PeriodicFunc[T]) {
-	p.Pool[key] = NewPeriodicFunc(p.Context, interval, fn)
+	p.NewWithConfig(key, PeriodicConfig[T]{Interval: interval}, fn)
+}
+
+/*
+NewWithConfig creates a new Periodic with a given configuration under given key.
+Check New for details.
+*/
+func (p *PeriodicPool[T]) NewWithConfig(key string, config PeriodicConfig[T], fn PeriodicFunc[T]) {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return
+	}
+	previous := p.entries[key]
+	entry := NewPeriodic(p.ctx, config, fn)
+	entry.register(p.opts.metrics, p.opts.name+":"+key)
+	p.entries[key] = entry
+	p.lock.Unlock()
+	// Stop replaced function outside of lock
+	if previous != nil {
+		previous.Stop()
+	}
+}
+
+// entry returns a Periodic for a key.
+func (p *PeriodicPool[T]) entry(key string) (*Periodic[T], error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	entry, ok := p.entries[key]
+	if !ok {
+		return nil, ErrPeriodicPoolMissingKey
+	}
+
+	return entry, nil
 }
 
 // Get allows to access specific PeriodicFunc with a given key.
 func (p *PeriodicPool[T]) Get(key string) (T, error) {
-	if p.Pool[key] == nil {
-		// Initialize empty value
+	entry, err := p.entry(key)
+	if err != nil {
 		var v T
-		// Return empty value and error
-		return v, ErrPeriodicPoolMissingKey
+		return v, err
 	}
-	// Return periodic result
-	return p.Pool[key]()
+
+	return entry.Get()
+}
+
+/*
+Wait waits for the first load of a function under given key.
+Check Periodic.Wait for details.
+*/
+func (p *PeriodicPool[T]) Wait(ctx context.Context, key string) error {
+	entry, err := p.entry(key)
+	if err != nil {
+		return err
+	}
+
+	return entry.Wait(ctx)
+}
+
+/*
+Refresh forces an immediate refresh of a function under given key.
+It doesn't wait for the refresh, use Status to check the result.
+*/
+func (p *PeriodicPool[T]) Refresh(key string) error {
+	entry, err := p.entry(key)
+	if err != nil {
+		return err
+	}
+	entry.Refresh()
+
+	return nil
+}
+
+/*
+Status returns a state of a function under given key.
+*/
+func (p *PeriodicPool[T]) Status(key string) (PeriodicStatus, error) {
+	entry, err := p.entry(key)
+	if err != nil {
+		return PeriodicStatus{}, err
+	}
+
+	return entry.Status(), nil
+}
+
+/*
+Stats returns statistics of a function under given key.
+*/
+func (p *PeriodicPool[T]) Stats(key string) (Stats, error) {
+	entry, err := p.entry(key)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return entry.Stats(), nil
+}
+
+/*
+Remove stops a function under given key and removes it from the pool
+(and from metrics registry).
+It waits for a running refresh to finish.
+*/
+func (p *PeriodicPool[T]) Remove(key string) error {
+	p.lock.Lock()
+	entry, ok := p.entries[key]
+	delete(p.entries, key)
+	p.lock.Unlock()
+	if !ok {
+		return ErrPeriodicPoolMissingKey
+	}
+	entry.Stop()
+
+	return nil
+}
+
+/*
+Keys returns sorted pool keys.
+*/
+func (p *PeriodicPool[T]) Keys() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	keys := make([]string, 0, len(p.entries))
+	for key := range p.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+/*
+Close stops all functions and waits for running refreshes to finish.
+Last values are still available with Get.
+Returns ErrPeriodicPoolClosed if called more than once.
+*/
+func (p *PeriodicPool[T]) Close() error {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return ErrPeriodicPoolClosed
+	}
+	p.closed = true
+	entries := make([]*Periodic[T], 0, len(p.entries))
+	for _, entry := range p.entries {
+		entries = append(entries, entry)
+	}
+	p.lock.Unlock()
+	// Stop all at once, then wait
+	for _, entry := range entries {
+		entry.cancel()
+	}
+	for _, entry := range entries {
+		<-entry.done
+	}
+
+	return nil
 }
 
 /*
 NewPeriodicPool is a PeriodicPool builder.
 Check PeriodicPool for details.
+Options, except WithMetrics, are ignored.
 
 Usage:
 
-	ctx, cancel := context.WithCancel(context.Background())
-	pool := NewPeriodicPool(context.Background())
+	pool := NewPeriodicPool[string](context.Background())
+	defer pool.Close()
 	pool.New("example", 1 * time.Minute, func() (string, error) {
-		time.Sleep(1 * time.Second) Imitate some work
-		return "Function cached result"
+		time.Sleep(1 * time.Second) // Imitate some work
+		return "Function cached result", nil
 	})
+	pool.Wait(ctx, "example")
 	log.Println(pool.Get("example")) // Value from getter
+	pool.Refresh("example") // Force refresh
+	pool.Remove("example") // Stop and remove
 */
-func NewPeriodicPool[T any](ctx context.Context) *PeriodicPool[T] {
+func NewPeriodicPool[T any](ctx context.Context, opts ...Option) *PeriodicPool[T] {
 	return &PeriodicPool[T]{
-		Pool:    map[string]PeriodicFunc[T]{},
-		Context: ctx,
+		ctx:     ctx,
+		opts:    newOptions(opts),
+		entries: map[string]*Periodic[T]{},
 	}
 }
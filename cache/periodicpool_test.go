@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPeriodicPoolRemove(t *testing.T) {
+	var calls int32
+	pool := NewPeriodicPool[int](context.Background())
+	defer pool.Close() //nolint:errcheck
+	pool.New("key", 5*time.Millisecond, func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 1, nil
+	})
+	if err := pool.Wait(context.Background(), "key"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := pool.Remove("key"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	removed := atomic.LoadInt32(&calls)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != removed {
+		t.Fatalf("expected no loads after Remove")
+	}
+	if _, err := pool.Get("key"); !errors.Is(err, ErrPeriodicPoolMissingKey) {
+		t.Fatalf("expected ErrPeriodicPoolMissingKey, got %v", err)
+	}
+	if err := pool.Remove("key"); !errors.Is(err, ErrPeriodicPoolMissingKey) {
+		t.Fatalf("expected ErrPeriodicPoolMissingKey, got %v", err)
+	}
+}
+
+func TestPeriodicPoolClose(t *testing.T) {
+	var calls int32
+	pool := NewPeriodicPool[int](context.Background())
+	for _, key := range []string{"a", "b", "c"} {
+		pool.New(key, 5*time.Millisecond, func() (int, error) {
+			atomic.AddInt32(&calls, 1)
+			return 1, nil
+		})
+	}
+	for _, key := range pool.Keys() {
+		pool.Wait(context.Background(), key) //nolint:errcheck
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	closed := atomic.LoadInt32(&calls)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != closed {
+		t.Fatalf("expected no loads after Close")
+	}
+	// Last values are kept, new functions are ignored
+	if value, err := pool.Get("a"); value != 1 || err != nil {
+		t.Fatalf("expected the last value after Close, got %d, %v", value, err)
+	}
+	pool.New("d", time.Minute, func() (int, error) { return 1, nil })
+	if _, err := pool.Get("d"); !errors.Is(err, ErrPeriodicPoolMissingKey) {
+		t.Fatalf("expected ErrPeriodicPoolMissingKey, got %v", err)
+	}
+	if err := pool.Close(); !errors.Is(err, ErrPeriodicPoolClosed) {
+		t.Fatalf("expected ErrPeriodicPoolClosed, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+/*
+Policy is an eviction policy, which tracks keys
+and chooses eviction victims.
+Policies are not required to be safe for concurrent use,
+cache calls them under shard lock.
+Built-in policies are LRU, LFU and TinyLFU.
+*/
+type Policy[K comparable] interface {
+	// Add tracks a new key
+	Add(key K)
+	// Access records a key access (hit, update, or miss for untracked key)
+	Access(key K)
+	// Remove stops tracking a key (deletion or expiration)
+	Remove(key K)
+	// Evict chooses a victim, stops tracking it and returns it.
+	// Returns false if there are no keys
+	Evict() (K, bool)
+}
+
+/*
+PolicyFunc creates a policy for a given capacity (number of entries, 0 if unknown).
+LRU, LFU and TinyLFU are matching it.
+
+Usage:
+
+	c := cache.New(cache.Config[string, int]{MaxEntries: 1000, Policy: cache.LFU[string]})
+*/
+type PolicyFunc[K comparable] func(capacity int) Policy[K]
+
+// LRU
+
+// lru is a least recently used policy.
+type lru[K comparable] struct {
+	order   *list.List // most recently used at the front
+	entries map[K]*list.Element
+}
+
+func (p *lru[K]) Add(key K) {
+	p.entries[key] = p.order.PushFront(key)
+}
+
+func (p *lru[K]) Access(key K) {
+	if element, ok := p.entries[key]; ok {
+		p.order.MoveToFront(element)
+	}
+}
+
+func (p *lru[K]) Remove(key K) {
+	if element, ok := p.entries[key]; ok {
+		p.order.Remove(element)
+		delete(p.entries, key)
+	}
+}
+
+func (p *lru[K]) Evict() (K, bool) {
+	element := p.order.Back()
+	if element == nil {
+		var zero K
+		return zero, false
+	}
+	key := element.Value.(K) //nolint:forcetypeassert
+	p.Remove(key)
+
+	return key, true
+}
+
+/*
+LRU creates a least recently used eviction policy.
+*/
+func LRU[K comparable](capacity int) Policy[K] {
+	return &lru[K]{order: list.New(), entries: map[K]*list.Element{}}
+}
+
+// LFU
+
+// lfuItem is a tracked key with access frequency.
+type lfuItem[K comparable] struct {
+	key   K
+	freq  int
+	tick  int
+	index int
+}
+
+// lfuHeap is a min-heap by frequency, then by last access.
+type lfuHeap[K comparable] []*lfuItem[K]
+
+func (h lfuHeap[K]) Len() int { return len(h) }
+
+func (h lfuHeap[K]) Less(a, b int) bool {
+	if h[a].freq != h[b].freq {
+		return h[a].freq < h[b].freq
+	}
+	return h[a].tick < h[b].tick
+}
+
+func (h lfuHeap[K]) Swap(a, b int) {
+	h[a], h[b] = h[b], h[a]
+	h[a].index, h[b].index = a, b
+}
+
+func (h *lfuHeap[K]) Push(x any) {
+	item := x.(*lfuItem[K]) //nolint:forcetypeassert
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *lfuHeap[K]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return item
+}
+
+// lfu is a least frequently used policy.
+// Ties are resolved as least recently used.
+type lfu[K comparable] struct {
+	heap    lfuHeap[K]
+	entries map[K]*lfuItem[K]
+	tick    int
+}
+
+func (p *lfu[K]) Add(key K) {
+	p.tick++
+	item := &lfuItem[K]{key: key, freq: 1, tick: p.tick}
+	heap.Push(&p.heap, item)
+	p.entries[key] = item
+}
+
+func (p *lfu[K]) Access(key K) {
+	if item, ok := p.entries[key]; ok {
+		p.tick++
+		item.freq++
+		item.tick = p.tick
+		heap.Fix(&p.heap, item.index)
+	}
+}
+
+func (p *lfu[K]) Remove(key K) {
+	if item, ok := p.entries[key]; ok {
+		heap.Remove(&p.heap, item.index)
+		delete(p.entries, key)
+	}
+}
+
+func (p *lfu[K]) Evict() (K, bool) {
+	if len(p.heap) == 0 {
+		var zero K
+		return zero, false
+	}
+	item := heap.Pop(&p.heap).(*lfuItem[K]) //nolint:forcetypeassert
+	delete(p.entries, item.key)
+
+	return item.key, true
+}
+
+/*
+LFU creates a least frequently used eviction policy.
+*/
+func LFU[K comparable](capacity int) Policy[K] {
+	return &lfu[K]{entries: map[K]*lfuItem[K]{}}
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLRUEvictionOrder(t *testing.T) {
+	p := LRU[int](3)
+	p.Add(1)
+	p.Add(2)
+	p.Add(3)
+	p.Access(1)
+	for _, expected := range []int{2, 3, 1} {
+		if key, ok := p.Evict(); !ok || key != expected {
+			t.Fatalf("expected %d to be evicted, got %d", expected, key)
+		}
+	}
+	if _, ok := p.Evict(); ok {
+		t.Fatalf("expected empty policy")
+	}
+}
+
+func TestLFUEvictionOrder(t *testing.T) {
+	p := LFU[int](3)
+	p.Add(1)
+	p.Add(2)
+	p.Add(3)
+	p.Access(1)
+	p.Access(1)
+	p.Access(3)
+	// 2 is the least frequent, 3 and 1 follow
+	for _, expected := range []int{2, 3, 1} {
+		if key, ok := p.Evict(); !ok || key != expected {
+			t.Fatalf("expected %d to be evicted, got %d", expected, key)
+		}
+	}
+	p.Add(4)
+	p.Remove(4)
+	if _, ok := p.Evict(); ok {
+		t.Fatalf("expected empty policy")
+	}
+}
+
+func TestTinyLFUResistsScan(t *testing.T) {
+	c := New(Config[string, int]{MaxEntries: 100, Policy: TinyLFU[string]})
+	// Popular keys
+	for round := 0; round < 10; round++ {
+		for i := 0; i < 50; i++ {
+			key := fmt.Sprintf("hot%d", i)
+			if _, ok := c.Get(key); !ok {
+				c.Set(key, i)
+			}
+		}
+	}
+	// One-time scan
+	for i := 0; i < 1000; i++ {
+		c.Set(fmt.Sprintf("scan%d", i), i)
+	}
+	if c.Len() != 100 {
+		t.Fatalf("expected 100 entries, got %d", c.Len())
+	}
+	hot := 0
+	for i := 0; i < 50; i++ {
+		if _, ok := c.Get(fmt.Sprintf("hot%d", i)); ok {
+			hot++
+		}
+	}
+	if hot < 40 {
+		t.Fatalf("expected popular keys to survive a scan, got %d of 50", hot)
+	}
+}
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yznts/zen/v3/async"
+)
+
+var (
+	ErrSnapshotFormat  = errors.New("invalid snapshot format")
+	ErrSnapshotVersion = errors.New("unsupported snapshot version")
+)
+
+// SnapshotMaxRecord limits a size of a single snapshot record in bytes,
+// larger (or corrupted) records are rejected with ErrSnapshotFormat.
+var SnapshotMaxRecord uint64 = 64 << 20
+
+// Snapshot layout: magic, version byte,
+// then records, each prefixed with uvarint length.
+const (
+	snapshotMagic   = "ZENCACHE"
+	snapshotVersion = 1
+)
+
+/*
+Snapshotter is a store, which might be dumped into a snapshot and restored from it.
+Cache, Tagged, Memo and DiskStore are implementing it.
+Snapshots contain values only, so Tagged tags and dependency links
+are not restored and must be attached again (f.e. with Tag and Depend).
+*/
+type Snapshotter[K comparable, V any] interface {
+	// Dump calls fn for each not expired entry with its expiration time
+	// (zero if entry doesn't expire), until fn returns false
+	Dump(fn func(key K, value V, expires time.Time) bool)
+	// Restore stores a value with a given expiration time
+	Restore(key K, value V, expires time.Time) error
+}
+
+// snapshotRecord is a serialized snapshot entry.
+type snapshotRecord[K comparable, V any] struct {
+	Key     K
+	Value   V
+	Expires time.Time
+}
+
+/*
+WriteSnapshot writes store entries into a versioned snapshot.
+Entries are serialized with a given codec (JSONCodec if nil),
+the same codec must be used for ReadSnapshot.
+Returns a number of written entries.
+
+Usage:
+
+	file, err := os.Create("users.snapshot")
+	defer file.Close()
+	count, err := cache.WriteSnapshot[int, *User](file, users, cache.GobCodec{})
+*/
+func WriteSnapshot[K comparable, V any](w io.Writer, store Snapshotter[K, V], codec Codec) (int, error) {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	out := bufio.NewWriter(w)
+	// Header
+	if _, err := out.WriteString(snapshotMagic); err != nil {
+		return 0, err
+	}
+	if err := out.WriteByte(snapshotVersion); err != nil {
+		return 0, err
+	}
+	// Records
+	var (
+		count  int
+		err    error
+		length [binary.MaxVarintLen64]byte
+	)
+	store.Dump(func(key K, value V, expires time.Time) bool {
+		var data []byte
+		data, err = codec.Marshal(snapshotRecord[K, V]{Key: key, Value: value, Expires: expires})
+		if err != nil {
+			return false
+		}
+		n := binary.PutUvarint(length[:], uint64(len(data)))
+		if _, err = out.Write(length[:n]); err != nil {
+			return false
+		}
+		if _, err = out.Write(data); err != nil {
+			return false
+		}
+		count++
+		return true
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, out.Flush()
+}
+
+/*
+ReadSnapshot restores store entries from a snapshot, written with WriteSnapshot.
+Expired entries are skipped, remaining TTLs are kept.
+Records over SnapshotMaxRecord are rejected with ErrSnapshotFormat.
+Returns a number of read entries (including skipped).
+
+Usage:
+
+	file, err := os.Open("users.snapshot")
+	defer file.Close()
+	count, err := cache.ReadSnapshot[int, *User](file, users, cache.GobCodec{})
+*/
+func ReadSnapshot[K comparable, V any](r io.Reader, store Snapshotter[K, V], codec Codec) (int, error) {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	in := bufio.NewReader(r)
+	// Header
+	header := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrSnapshotFormat, err)
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return 0, ErrSnapshotFormat
+	}
+	if version := header[len(snapshotMagic)]; version != snapshotVersion {
+		return 0, fmt.Errorf("%w: %d", ErrSnapshotVersion, version)
+	}
+	// Records
+	count := 0
+	for {
+		length, err := binary.ReadUvarint(in)
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("%w: %s", ErrSnapshotFormat, err)
+		}
+		if length > SnapshotMaxRecord {
+			return count, fmt.Errorf("%w: record size %d exceeds limit", ErrSnapshotFormat, length)
+		}
+		// Truncated input doesn't allocate a full length
+		data, err := io.ReadAll(io.LimitReader(in, int64(length)))
+		if err != nil {
+			return count, fmt.Errorf("%w: %s", ErrSnapshotFormat, err)
+		}
+		if uint64(len(data)) != length {
+			return count, fmt.Errorf("%w: %s", ErrSnapshotFormat, io.ErrUnexpectedEOF)
+		}
+		record := snapshotRecord[K, V]{}
+		if err := codec.Unmarshal(data, &record); err != nil {
+			return count, err
+		}
+		if err := store.Restore(record.Key, record.Value, record.Expires); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+/*
+LoadingStore is a store, which loads missing values on access.
+Cache, Tagged and DiskStore are implementing it.
+*/
+type LoadingStore[K comparable, V any] interface {
+	GetOrLoad(key K, loader func(K) (V, error)) (V, error)
+}
+
+/*
+Warm loads given keys into a store with a loader,
+running at most concurrency loads at a time.
+Already stored keys are not loaded again.
+All keys are processed even if some loads fail,
+returns a number of successful keys (including already stored) and the first error.
+
+Usage:
+
+	count, err := cache.Warm[int, *User](users, popular, db.User, 8)
+*/
+func Warm[K comparable, V any](store LoadingStore[K, V], keys []K, loader func(K) (V, error), concurrency int) (int, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	in, out := async.Pool(concurrency, func(key K) error {
+		_, err := store.GetOrLoad(key, loader)
+		return err
+	})
+	go func() {
+		for _, key := range keys {
+			in <- key
+		}
+		close(in)
+	}()
+	var (
+		count int
+		first error
+	)
+	for err := range out {
+		switch {
+		case err == nil:
+			count++
+		case first == nil:
+			first = err
+		}
+	}
+
+	return count, first
+}
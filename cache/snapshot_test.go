@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// snapshotTestRecords is a Snapshotter over a list of records.
+type snapshotTestRecords []snapshotRecord[string, int]
+
+func (s snapshotTestRecords) Dump(fn func(key string, value int, expires time.Time) bool) {
+	for _, record := range s {
+		if !fn(record.Key, record.Value, record.Expires) {
+			return
+		}
+	}
+}
+
+func (s *snapshotTestRecords) Restore(key string, value int, expires time.Time) error {
+	*s = append(*s, snapshotRecord[string, int]{key, value, expires})
+	return nil
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, codec := range []Codec{JSONCodec{}, GobCodec{}} {
+		disk, err := NewDiskStore[string, int](DiskConfig{Dir: t.TempDir(), Codec: codec})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores := map[string]func() Snapshotter[string, int]{
+			"cache": func() Snapshotter[string, int] { return New[string, int]() },
+			"memo": func() Snapshotter[string, int] {
+				return NewMemo(time.Minute, func(string) (int, error) { return 0, nil })
+			},
+			"disk": func() Snapshotter[string, int] { return disk },
+		}
+		for name, store := range stores {
+			source, target := store(), New[string, int]()
+			if err := source.Restore("a", 1, time.Now().Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if err := source.Restore("b", 2, time.Time{}); err != nil {
+				t.Fatal(err)
+			}
+			buffer := &bytes.Buffer{}
+			if count, err := WriteSnapshot[string, int](buffer, source, codec); err != nil || count != 2 {
+				t.Fatalf("%s %T: expected 2 written entries, got %d, %v", name, codec, count, err)
+			}
+			if count, err := ReadSnapshot[string, int](buffer, target, codec); err != nil || count != 2 {
+				t.Fatalf("%s %T: expected 2 read entries, got %d, %v", name, codec, count, err)
+			}
+			if value, ok := target.Get("a"); !ok || value != 1 {
+				t.Fatalf("%s %T: expected a=1, got %d", name, codec, value)
+			}
+			if value, ok := target.Get("b"); !ok || value != 2 {
+				t.Fatalf("%s %T: expected b=2, got %d", name, codec, value)
+			}
+		}
+	}
+}
+
+func TestSnapshotSkipsExpired(t *testing.T) {
+	source := snapshotTestRecords{
+		{Key: "expired", Value: 1, Expires: time.Now().Add(-time.Minute)},
+		{Key: "fresh", Value: 2, Expires: time.Now().Add(time.Minute)},
+	}
+	buffer := &bytes.Buffer{}
+	if _, err := WriteSnapshot[string, int](buffer, &source, nil); err != nil {
+		t.Fatal(err)
+	}
+	target := New[string, int]()
+	if _, err := ReadSnapshot[string, int](buffer, target, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := target.Get("expired"); ok {
+		t.Fatal("expected expired record to be skipped")
+	}
+	if _, ok := target.Get("fresh"); !ok {
+		t.Fatal("expected fresh record to be restored")
+	}
+}
+
+func TestSnapshotFormat(t *testing.T) {
+	length := make([]byte, binary.MaxVarintLen64)
+	huge := length[:binary.PutUvarint(length, 1<<62)]
+	tests := map[string]struct {
+		data []byte
+		err  error
+	}{
+		"magic":     {[]byte("NOTCACHE\x01"), ErrSnapshotFormat},
+		"short":     {[]byte("ZEN"), ErrSnapshotFormat},
+		"version":   {[]byte(snapshotMagic + "\x09"), ErrSnapshotVersion},
+		"huge":      {append([]byte(snapshotMagic+"\x01"), huge...), ErrSnapshotFormat},
+		"truncated": {append([]byte(snapshotMagic+"\x01"), 0x10, '{'), ErrSnapshotFormat},
+	}
+	for name, test := range tests {
+		target := &snapshotTestRecords{}
+		if _, err := ReadSnapshot[string, int](bytes.NewReader(test.data), target, nil); !errors.Is(err, test.err) {
+			t.Fatalf("%s: expected %v, got %v", name, test.err, err)
+		}
+	}
+}
+
+func TestWarm(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		running  int32
+		peak     int32
+		failures = map[int]error{3: errors.New("first"), 7: errors.New("second")}
+	)
+	c := New[int, int]()
+	c.Set(0, 0)
+	keys := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	count, err := Warm[int, int](c, keys, func(key int) (int, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		lock.Lock()
+		if current > peak {
+			peak = current
+		}
+		lock.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		if key == 7 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err := failures[key]; err != nil {
+			return 0, err
+		}
+		return key * 10, nil
+	}, 2)
+	if count != 8 {
+		t.Fatalf("expected 8 successful keys, got %d", count)
+	}
+	if err == nil || err.Error() != "first" {
+		t.Fatalf("expected the first error, got %v", err)
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent loads, got %d", peak)
+	}
+	if value, ok := c.Get(9); !ok || value != 90 {
+		t.Fatalf("expected warmed key 9, got %d", value)
+	}
+	if _, ok := c.Get(3); ok {
+		t.Fatal("expected failed key not to be stored")
+	}
+}
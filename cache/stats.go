@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// evictReasons is a number of eviction reasons.
+const evictReasons = 4
+
+/*
+Stats is a cache statistics snapshot.
+Counters are accumulated since cache creation.
+*/
+type Stats struct {
+	// Hits is a number of lookups, served from cache (including stale values)
+	Hits uint64
+	// Misses is a number of lookups, which found no value
+	Misses uint64
+	// Loads is a number of executed loads (including background refreshes)
+	Loads uint64
+	// LoadErrors is a number of failed loads
+	LoadErrors uint64
+	// LoadTime is a total loads duration
+	LoadTime time.Duration
+	// Evictions is a number of removed entries by reason
+	Evictions map[EvictReason]uint64
+}
+
+/*
+HitRatio returns hits share of all lookups (0..1).
+Returns 0 if there were no lookups.
+*/
+func (s Stats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+
+	return 0
+}
+
+/*
+AvgLoadTime returns an average load duration.
+Returns 0 if there were no loads.
+*/
+func (s Stats) AvgLoadTime() time.Duration {
+	if s.Loads > 0 {
+		return s.LoadTime / time.Duration(s.Loads)
+	}
+
+	return 0
+}
+
+/*
+StatsSource is anything, which reports cache statistics.
+All cache types in the package are implementing it.
+*/
+type StatsSource interface {
+	Stats() Stats
+}
+
+// counters is a lock-free statistics collector.
+// Must be allocated separately (as a pointer) to keep 64-bit alignment.
+type counters struct {
+	hits       uint64
+	misses     uint64
+	loads      uint64
+	loaderrors uint64
+	loadtime   int64
+	evictions  [evictReasons]uint64
+}
+
+// hit records a lookup result.
+func (c *counters) hit(ok bool) {
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+
+// load records a finished load, started at a given time.
+func (c *counters) load(start time.Time, err error) {
+	atomic.AddUint64(&c.loads, 1)
+	atomic.AddInt64(&c.loadtime, int64(time.Since(start)))
+	if err != nil {
+		atomic.AddUint64(&c.loaderrors, 1)
+	}
+}
+
+// evict records an eviction.
+func (c *counters) evict(reason EvictReason) {
+	if reason >= 0 && reason < evictReasons {
+		atomic.AddUint64(&c.evictions[reason], 1)
+	}
+}
+
+// snapshot returns current statistics.
+func (c *counters) snapshot() Stats {
+	stats := Stats{
+		Hits:       atomic.LoadUint64(&c.hits),
+		Misses:     atomic.LoadUint64(&c.misses),
+		Loads:      atomic.LoadUint64(&c.loads),
+		LoadErrors: atomic.LoadUint64(&c.loaderrors),
+		LoadTime:   time.Duration(atomic.LoadInt64(&c.loadtime)),
+		Evictions:  make(map[EvictReason]uint64, evictReasons),
+	}
+	for reason := EvictReason(0); reason < evictReasons; reason++ {
+		stats.Evictions[reason] = atomic.LoadUint64(&c.evictions[reason])
+	}
+
+	return stats
+}
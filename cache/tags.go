@@ -0,0 +1,219 @@
+package cache
+
+import "sync"
+
+/*
+Tagged is a Cache with tag- and dependency-based invalidation.
+Entries might be tagged (like "user:42", "org:7") to drop them all with InvalidateTag,
+and linked to parent entries with Depend, so removing or replacing a parent
+drops its derived children (recursively).
+Tags and links are cleaned up on any entry removal (expiration, eviction, deletion),
+tags are kept on value replacement.
+All Cache methods are available as well.
+Use NewTagged to create it.
+
+Usage:
+
+	c := cache.NewTagged(cache.Config[string, any]{TTL: 10 * time.Minute})
+	c.SetWithTags("user:42:profile", profile, "user:42")
+	c.SetWithTags("org:7:members", members, "org:7", "user:42")
+	c.Set("user:42:feed", feed)
+	c.Depend("user:42:feed", "user:42:profile")
+	c.InvalidateTag("user:42") // Drops all three entries
+*/
+type Tagged[K comparable, V any] struct {
+	*Cache[K, V]
+
+	lock     sync.Mutex
+	tags     map[string]map[K]struct{} // tag -> keys
+	keys     map[K]map[string]struct{} // key -> tags
+	children map[K]map[K]struct{}      // parent -> children
+	parents  map[K]map[K]struct{}      // child -> parents
+}
+
+/*
+SetWithTags stores a value with a default TTL and attaches given tags.
+Check Tag for details.
+*/
+func (t *Tagged[K, V]) SetWithTags(key K, value V, tags ...string) {
+	t.Tag(key, tags...)
+	t.Set(key, value)
+}
+
+/*
+Tag attaches tags to a key.
+Tags might be attached before the value is stored.
+*/
+func (t *Tagged[K, V]) Tag(key K, tags ...string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, tag := range tags {
+		taggedLink(t.tags, tag, key)
+		taggedLink(t.keys, key, tag)
+	}
+}
+
+/*
+Tags returns tags, attached to a key.
+*/
+func (t *Tagged[K, V]) Tags(key K) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tags := make([]string, 0, len(t.keys[key]))
+	for tag := range t.keys[key] {
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+/*
+Depend links a child key to parent keys,
+so the child is dropped when any of parents is removed or replaced.
+Links are dropped with the child or the parent.
+*/
+func (t *Tagged[K, V]) Depend(child K, parents ...K) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, parent := range parents {
+		taggedLink(t.children, parent, child)
+		taggedLink(t.parents, child, parent)
+	}
+}
+
+/*
+InvalidateTag drops all entries with any of given tags (and their children).
+Returns a number of dropped tagged entries.
+*/
+func (t *Tagged[K, V]) InvalidateTag(tags ...string) int {
+	t.lock.Lock()
+	var keys []K
+	for _, tag := range tags {
+		for key := range t.tags[tag] {
+			keys = append(keys, key)
+		}
+	}
+	t.lock.Unlock()
+
+	return t.Invalidate(keys...)
+}
+
+/*
+Invalidate drops entries with given keys (and their children).
+Returns a number of dropped entries.
+*/
+func (t *Tagged[K, V]) Invalidate(keys ...K) int {
+	count := 0
+	for _, key := range keys {
+		if t.Delete(key) {
+			count++
+		} else {
+			// Not stored, but might be indexed
+			t.evicted(key, EvictDeleted)
+		}
+	}
+
+	return count
+}
+
+/*
+Listen applies invalidations from a given Invalidator, until returned function is called.
+*/
+func (t *Tagged[K, V]) Listen(invalidator Invalidator[K]) func() {
+	return invalidator.Subscribe(func(msg Invalidation[K]) {
+		t.Invalidate(msg.Keys...)
+		t.InvalidateTag(msg.Tags...)
+	})
+}
+
+/*
+Clear drops all entries, tags and links
+(including ones, attached to keys without stored values).
+*/
+func (t *Tagged[K, V]) Clear() {
+	t.Cache.Clear()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.tags = map[string]map[K]struct{}{}
+	t.keys = map[K]map[string]struct{}{}
+	t.children = map[K]map[K]struct{}{}
+	t.parents = map[K]map[K]struct{}{}
+}
+
+// evicted cleans up a removed entry index and drops its children.
+func (t *Tagged[K, V]) evicted(key K, reason EvictReason) {
+	t.lock.Lock()
+	// Children are derived from the old value, even if it's replaced
+	children := t.children[key]
+	delete(t.children, key)
+	for child := range children {
+		taggedUnlink(t.parents, child, key)
+	}
+	// Tags and parent links are kept on replacement
+	if reason != EvictReplaced {
+		for tag := range t.keys[key] {
+			taggedUnlink(t.tags, tag, key)
+		}
+		delete(t.keys, key)
+		for parent := range t.parents[key] {
+			taggedUnlink(t.children, parent, key)
+		}
+		delete(t.parents, key)
+	}
+	t.lock.Unlock()
+	// Cascade outside of lock, Delete reports back to evicted
+	for child := range children {
+		t.Invalidate(child)
+	}
+}
+
+// taggedLink adds a value to an index set.
+func taggedLink[A, B comparable](index map[A]map[B]struct{}, a A, b B) {
+	if index[a] == nil {
+		index[a] = map[B]struct{}{}
+	}
+	index[a][b] = struct{}{}
+}
+
+// taggedUnlink removes a value from an index set.
+func taggedUnlink[A, B comparable](index map[A]map[B]struct{}, a A, b B) {
+	delete(index[a], b)
+	if len(index[a]) == 0 {
+		delete(index, a)
+	}
+}
+
+/*
+NewTagged is a Tagged builder.
+Accepts the same configuration as New,
+OnEvict is still called for each removed entry.
+Check Tagged for details.
+*/
+func NewTagged[K comparable, V any](config ...Config[K, V]) *Tagged[K, V] {
+	if len(config) == 0 {
+		config = append(config, Config[K, V]{})
+	}
+	cfg := config[0]
+	t := &Tagged[K, V]{
+		tags:     map[string]map[K]struct{}{},
+		keys:     map[K]map[string]struct{}{},
+		children: map[K]map[K]struct{}{},
+		parents:  map[K]map[K]struct{}{},
+	}
+	// Hook into evictions to keep index consistent
+	onevict := cfg.OnEvict
+	cfg.OnEvict = func(key K, value V, reason EvictReason) {
+		t.evicted(key, reason)
+		if onevict != nil {
+			onevict(key, value, reason)
+		}
+	}
+	t.Cache = New(cfg)
+
+	return t
+}
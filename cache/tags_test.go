@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// taggedIndexLen returns a total size of tagged indexes.
+func taggedIndexLen[K comparable, V any](t *Tagged[K, V]) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return len(t.tags) + len(t.keys) + len(t.children) + len(t.parents)
+}
+
+func TestTaggedInvalidateTag(t *testing.T) {
+	c := NewTagged[string, int]()
+	c.SetWithTags("a", 1, "user:1")
+	c.SetWithTags("b", 2, "user:1", "org:1")
+	c.SetWithTags("c", 3, "org:1")
+	c.Set("d", 4)
+	if count := c.InvalidateTag("user:1", "org:1"); count != 3 {
+		t.Fatalf("expected 3 dropped entries, got %d", count)
+	}
+	if c.Len() != 1 {
+		t.Fatalf("expected untagged entry to stay, got %d entries", c.Len())
+	}
+	if n := taggedIndexLen(c); n != 0 {
+		t.Fatalf("expected empty index, got %d", n)
+	}
+}
+
+func TestTaggedDependCascade(t *testing.T) {
+	c := NewTagged[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.Depend("b", "a")
+	c.Depend("c", "b")
+	c.Delete("a")
+	if c.Len() != 0 {
+		t.Fatalf("expected children to be dropped recursively, got %d entries", c.Len())
+	}
+	if n := taggedIndexLen(c); n != 0 {
+		t.Fatalf("expected empty index, got %d", n)
+	}
+}
+
+func TestTaggedDependCycle(t *testing.T) {
+	c := NewTagged[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Depend("a", "b")
+	c.Depend("b", "a")
+	done := make(chan int)
+	go func() { done <- c.Invalidate("a") }()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected cyclic dependencies invalidation to finish")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expected both entries to be dropped, got %d entries", c.Len())
+	}
+	if n := taggedIndexLen(c); n != 0 {
+		t.Fatalf("expected empty index, got %d", n)
+	}
+}
+
+func TestTaggedReplace(t *testing.T) {
+	c := NewTagged[string, int]()
+	c.SetWithTags("parent", 1, "tag")
+	c.Set("child", 2)
+	c.Depend("child", "parent")
+	c.Set("parent", 3)
+	if _, ok := c.Get("child"); ok {
+		t.Fatal("expected child to be dropped on parent replacement")
+	}
+	if tags := c.Tags("parent"); len(tags) != 1 || tags[0] != "tag" {
+		t.Fatalf("expected tags to be kept on replacement, got %v", tags)
+	}
+	if count := c.InvalidateTag("tag"); count != 1 {
+		t.Fatalf("expected replaced entry to be invalidated by tag, got %d", count)
+	}
+}
+
+func TestTaggedCleanup(t *testing.T) {
+	c := NewTagged(Config[string, int]{TTL: 10 * time.Millisecond})
+	c.SetWithTags("a", 1, "tag")
+	c.Set("b", 2)
+	c.Depend("b", "a")
+	time.Sleep(20 * time.Millisecond)
+	c.Get("a")
+	c.Get("b")
+	if n := taggedIndexLen(c); n != 0 {
+		t.Fatalf("expected index to be cleaned up on expiration, got %d", n)
+	}
+	// Clear drops tags of not stored keys as well
+	c.SetWithTags("c", 3, "tag")
+	c.Tag("pending", "tag")
+	c.Depend("pending", "c")
+	c.Clear()
+	if n := taggedIndexLen(c); n != 0 {
+		t.Fatalf("expected index to be cleaned up on clear, got %d", n)
+	}
+}
+
+func TestChanInvalidator(t *testing.T) {
+	invalidator := NewChanInvalidator[string](4)
+	defer invalidator.Close()
+	c := NewTagged[string, int]()
+	c.SetWithTags("a", 1, "tag")
+	c.Set("b", 2)
+	c.Set("c", 3)
+	var (
+		lock     sync.Mutex
+		received []string
+		wg       sync.WaitGroup
+	)
+	stop := c.Listen(invalidator)
+	wg.Add(2)
+	unsubscribe := invalidator.Subscribe(func(msg Invalidation[string]) {
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, msg.Keys...)
+		received = append(received, msg.Tags...)
+		wg.Done()
+	})
+	if err := invalidator.Publish(Invalidation[string]{Tags: []string{"tag"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := invalidator.Publish(Invalidation[string]{Keys: []string{"b"}}); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	// Listener is delivered in own goroutine
+	deadline := time.Now().Add(time.Second)
+	for c.Len() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok := c.Get("c"); !ok || c.Len() != 1 {
+		t.Fatalf("expected only c to stay, got %d entries", c.Len())
+	}
+	lock.Lock()
+	sort.Strings(received)
+	if len(received) != 2 || received[0] != "b" || received[1] != "tag" {
+		t.Fatalf("expected both messages to be received, got %v", received)
+	}
+	lock.Unlock()
+	// Unsubscribed handlers are not called
+	unsubscribe()
+	stop()
+	if err := invalidator.Publish(Invalidation[string]{Keys: []string{"c"}}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("expected unsubscribed listener not to invalidate")
+	}
+}
+
+func TestChanInvalidatorCloseFull(t *testing.T) {
+	invalidator := NewChanInvalidator[string](1)
+	var (
+		block    = make(chan struct{})
+		started  = make(chan struct{}, 1)
+		returned = make(chan error)
+	)
+	defer close(block)
+	invalidator.Subscribe(func(msg Invalidation[string]) {
+		started <- struct{}{}
+		<-block
+	})
+	// First message blocks the handler, second fills the buffer
+	if err := invalidator.Publish(Invalidation[string]{Keys: []string{"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := invalidator.Publish(Invalidation[string]{Keys: []string{"2"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Third one waits for a free buffer
+	go func() {
+		returned <- invalidator.Publish(Invalidation[string]{Keys: []string{"3"}})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		invalidator.Close()
+		close(closed)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("expected blocked publisher to be released on close")
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("expected close to finish")
+	}
+	if err := invalidator.Publish(Invalidation[string]{}); !errors.Is(err, ErrInvalidatorClosed) {
+		t.Fatalf("expected ErrInvalidatorClosed, got %v", err)
+	}
+	if unsubscribe := invalidator.Subscribe(func(Invalidation[string]) {}); unsubscribe == nil {
+		t.Fatal("expected no-op unsubscribe after close")
+	}
+}
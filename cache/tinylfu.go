@@ -0,0 +1,227 @@
+package cache
+
+import "container/list"
+
+// TinyLFU defaults.
+const (
+	tinylfuCapacity = 1024 // capacity, assumed if unknown
+	tinylfuWindow   = 0.01 // window share of capacity
+	tinylfuProtect  = 0.8  // protected share of main space
+)
+
+// sketch is a count-min sketch with 4 rows of saturating counters
+// and periodic aging (counters are halved after a sample of additions).
+type sketch struct {
+	rows      [4][]uint8
+	mask      uint64
+	additions int
+	sample    int
+}
+
+// newSketch creates a sketch for a given capacity.
+func newSketch(capacity int) *sketch {
+	width := 16
+	for width < capacity {
+		width *= 2
+	}
+	s := &sketch{mask: uint64(width - 1), sample: 10 * width}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+
+	return s
+}
+
+// index returns a counter index for a row.
+func (s *sketch) index(hash uint64, row int) uint64 {
+	hash = (hash + uint64(row)*0x9e3779b97f4a7c15) * 0xbf58476d1ce4e5b9
+	hash ^= hash >> 31
+
+	return hash & s.mask
+}
+
+// increment increments key counters.
+func (s *sketch) increment(hash uint64) {
+	for i := range s.rows {
+		if idx := s.index(hash, i); s.rows[i][idx] < 15 {
+			s.rows[i][idx]++
+		}
+	}
+	// Aging
+	if s.additions++; s.additions >= s.sample {
+		for i := range s.rows {
+			for j := range s.rows[i] {
+				s.rows[i][j] /= 2
+			}
+		}
+		s.additions /= 2
+	}
+}
+
+// estimate returns key frequency estimation.
+func (s *sketch) estimate(hash uint64) uint8 {
+	min := uint8(15)
+	for i := range s.rows {
+		if value := s.rows[i][s.index(hash, i)]; value < min {
+			min = value
+		}
+	}
+
+	return min
+}
+
+// tinylfuSegment is a key location.
+type tinylfuSegment int
+
+const (
+	tinylfuInWindow tinylfuSegment = iota
+	tinylfuInProbation
+	tinylfuInProtected
+)
+
+// tinylfuEntry is a tracked key.
+type tinylfuEntry[K comparable] struct {
+	key     K
+	hash    uint64
+	segment tinylfuSegment
+}
+
+// tinylfu is a W-TinyLFU policy:
+// new keys are entering a small LRU window,
+// window overflow is moved into the main segmented LRU (probation/protected),
+// and on eviction the latest admitted key stays only if it's more frequent
+// than the probation victim.
+type tinylfu[K comparable] struct {
+	capacity  int
+	sketch    *sketch
+	window    *list.List
+	probation *list.List
+	protected *list.List
+	entries   map[K]*list.Element
+}
+
+func (p *tinylfu[K]) Add(key K) {
+	hash := hashKey(key)
+	p.sketch.increment(hash)
+	p.entries[key] = p.window.PushFront(&tinylfuEntry[K]{key: key, hash: hash, segment: tinylfuInWindow})
+	// Window overflow moves into main space as an admission candidate
+	for p.window.Len() > p.windowCapacity() {
+		entry := p.window.Remove(p.window.Back()).(*tinylfuEntry[K]) //nolint:forcetypeassert
+		entry.segment = tinylfuInProbation
+		p.entries[entry.key] = p.probation.PushFront(entry)
+	}
+}
+
+func (p *tinylfu[K]) Access(key K) {
+	element, ok := p.entries[key]
+	// Misses are counted as well, to admit popular keys on the next add
+	if !ok {
+		p.sketch.increment(hashKey(key))
+		return
+	}
+	entry := element.Value.(*tinylfuEntry[K]) //nolint:forcetypeassert
+	p.sketch.increment(entry.hash)
+	switch entry.segment {
+	case tinylfuInWindow:
+		p.window.MoveToFront(element)
+	case tinylfuInProtected:
+		p.protected.MoveToFront(element)
+	case tinylfuInProbation:
+		// Promote to protected, demoting protected victim if full
+		p.probation.Remove(element)
+		entry.segment = tinylfuInProtected
+		p.entries[key] = p.protected.PushFront(entry)
+		if p.protected.Len() > p.protectedCapacity() {
+			demoted := p.protected.Remove(p.protected.Back()).(*tinylfuEntry[K]) //nolint:forcetypeassert
+			demoted.segment = tinylfuInProbation
+			p.entries[demoted.key] = p.probation.PushFront(demoted)
+		}
+	}
+}
+
+func (p *tinylfu[K]) Remove(key K) {
+	if element, ok := p.entries[key]; ok {
+		p.segment(element).Remove(element)
+		delete(p.entries, key)
+	}
+}
+
+func (p *tinylfu[K]) Evict() (K, bool) {
+	// The latest admission candidate competes with probation victim,
+	// the less frequent one is evicted
+	candidate, victim := p.probation.Front(), p.probation.Back()
+	if candidate != nil && candidate != victim {
+		centry := candidate.Value.(*tinylfuEntry[K]) //nolint:forcetypeassert
+		ventry := victim.Value.(*tinylfuEntry[K])    //nolint:forcetypeassert
+		if p.sketch.estimate(centry.hash) > p.sketch.estimate(ventry.hash) {
+			return p.evict(victim)
+		}
+		return p.evict(candidate)
+	}
+	// Evict from any segment
+	for _, segment := range []*list.List{p.probation, p.protected, p.window} {
+		if element := segment.Back(); element != nil {
+			return p.evict(element)
+		}
+	}
+	var zero K
+
+	return zero, false
+}
+
+// evict stops tracking an element and returns its key.
+func (p *tinylfu[K]) evict(element *list.Element) (K, bool) {
+	entry := element.Value.(*tinylfuEntry[K]) //nolint:forcetypeassert
+	p.segment(element).Remove(element)
+	delete(p.entries, entry.key)
+
+	return entry.key, true
+}
+
+// segment returns an element list.
+func (p *tinylfu[K]) segment(element *list.Element) *list.List {
+	switch element.Value.(*tinylfuEntry[K]).segment { //nolint:forcetypeassert
+	case tinylfuInProbation:
+		return p.probation
+	case tinylfuInProtected:
+		return p.protected
+	case tinylfuInWindow:
+	}
+
+	return p.window
+}
+
+// windowCapacity returns window size, at least 1.
+func (p *tinylfu[K]) windowCapacity() int {
+	if size := int(float64(p.capacity) * tinylfuWindow); size > 1 {
+		return size
+	}
+
+	return 1
+}
+
+// protectedCapacity returns protected segment size.
+func (p *tinylfu[K]) protectedCapacity() int {
+	return int(float64(p.capacity-p.windowCapacity()) * tinylfuProtect)
+}
+
+/*
+TinyLFU creates a W-TinyLFU eviction policy.
+It keeps frequently used keys, resisting one-time scans,
+while a small LRU window keeps recent bursts.
+Frequencies are estimated with a compact count-min sketch.
+*/
+func TinyLFU[K comparable](capacity int) Policy[K] {
+	if capacity <= 0 {
+		capacity = tinylfuCapacity
+	}
+
+	return &tinylfu[K]{
+		capacity:  capacity,
+		sketch:    newSketch(capacity),
+		window:    list.New(),
+		probation: list.New(),
+		protected: list.New(),
+		entries:   map[K]*list.Element{},
+	}
+}
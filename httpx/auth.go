@@ -0,0 +1,358 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yznts/zen/v3/b64"
+	"github.com/yznts/zen/v3/cache"
+)
+
+/*
+TokenFetchTimeout is a default ClientCredentials token request timeout.
+*/
+var TokenFetchTimeout = 30 * time.Second
+
+var (
+	ErrSignMissingHeader = errors.New("signed header is missing")
+	ErrTokenMissing      = errors.New("token response doesn't contain access_token")
+)
+
+// Builder methods
+
+/*
+BasicAuth sets a basic authorization header.
+*/
+func (r *RequestBuilder) BasicAuth(user, pass string) *RequestBuilder {
+	r.header["Authorization"] = []string{"Basic " + b64.Base64(user+":"+pass)}
+
+	return r
+}
+
+/*
+BearerToken sets a bearer authorization header.
+*/
+func (r *RequestBuilder) BearerToken(token string) *RequestBuilder {
+	r.header["Authorization"] = []string{"Bearer " + token}
+
+	return r
+}
+
+// HMAC signing
+
+/*
+HMACSigner signs requests with HMAC, following HTTP Signatures draft
+(draft-cavage-http-signatures) format:
+
+	Signature: keyId="...",algorithm="hmac-sha256",headers="(request-target) host date",signature="..."
+
+Headers are lower-case header names, with a few special values:
+"(request-target)" (method and request uri) and "digest"
+(SHA-256 body digest, calculated if header is missing).
+Date header is set automatically, if signed and missing.
+
+Usage:
+
+	signer := &httpx.HMACSigner{KeyID: "app", Key: []byte("secret")}
+	client := httpx.Client(signer.Transport)
+*/
+type HMACSigner struct {
+	KeyID string
+	Key   []byte
+	// Headers to sign. Defaults to "(request-target)", "host", "date"
+	Headers []string
+	// Hash function. Defaults to sha256.New
+	Hash func() hash.Hash
+	// Algorithm name, reported in signature. Defaults to "hmac-sha256"
+	Algorithm string
+}
+
+/*
+Sign signs a request in-place.
+*/
+func (s *HMACSigner) Sign(req *http.Request) error {
+	headers := s.Headers
+	if len(headers) == 0 {
+		headers = []string{"(request-target)", "host", "date"}
+	}
+	// Compose signing string
+	lines := make([]string, 0, len(headers))
+	for _, header := range headers {
+		header = strings.ToLower(header)
+		value, err := s.value(req, header)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, header+": "+value)
+	}
+	// Sign
+	hashfn := s.Hash
+	if hashfn == nil {
+		hashfn = sha256.New
+	}
+	mac := hmac.New(hashfn, s.Key)
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	// Set header
+	algorithm := s.Algorithm
+	if algorithm == "" {
+		algorithm = "hmac-sha256"
+	}
+	req.Header.Set("Signature", fmt.Sprintf(
+		`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.KeyID, algorithm, strings.ToLower(strings.Join(headers, " ")), signature,
+	))
+
+	return nil
+}
+
+// value resolves a signed header value, setting missing automatic headers.
+func (s *HMACSigner) value(req *http.Request, header string) (string, error) {
+	switch header {
+	case "(request-target)":
+		return strings.ToLower(req.Method) + " " + req.URL.RequestURI(), nil
+	case "host":
+		if req.Host != "" {
+			return req.Host, nil
+		}
+		return req.URL.Host, nil
+	case "date":
+		if req.Header.Get("Date") == "" {
+			req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+		}
+	case "digest":
+		if req.Header.Get("Digest") == "" {
+			body, err := readBody(req)
+			if err != nil {
+				return "", err
+			}
+			sum := sha256.Sum256(body)
+			req.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]))
+		}
+	}
+	value := req.Header.Values(header)
+	if len(value) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrSignMissingHeader, header)
+	}
+
+	return strings.Join(value, ", "), nil
+}
+
+/*
+Transport is a ClientMiddleware, which signs outgoing requests.
+*/
+func (s *HMACSigner) Transport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		if err := s.Sign(req); err != nil {
+			return nil, err
+		}
+
+		return next.RoundTrip(req)
+	})
+}
+
+// readBody reads request body, keeping it readable for further processing.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return []byte{}, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	return data, nil
+}
+
+// OAuth2
+
+/*
+Token is an OAuth2 access token.
+*/
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int       `json:"expires_in"`
+	Expiry      time.Time `json:"-"`
+}
+
+/*
+ClientCredentials is an OAuth2 client-credentials token source.
+Token is cached (with cache.Cache) until expiry.
+When a given fraction of token lifetime is passed (RefreshAhead),
+it's still returned, while a new one is fetched in background.
+Expired token is fetched synchronously, concurrent callers are waiting
+for a single fetch. Fetch is shared between callers,
+so it's not canceled with a caller context (it's limited with FetchTimeout instead),
+while callers stop waiting on their context cancellation.
+
+Usage:
+
+	source := &httpx.ClientCredentials{
+		TokenURL:     "https://auth.example.com/oauth/token",
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"read"},
+	}
+	client := httpx.Client(source.Transport)
+*/
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Params are extra token request parameters, like "audience"
+	Params url.Values
+	// RefreshAhead is a fraction (0..1) of token lifetime to start background refresh.
+	// Defaults to 0.8
+	RefreshAhead float64
+	// Client is used for token requests. Defaults to http.DefaultClient
+	Client *http.Client
+	// FetchTimeout limits a token request.
+	// Defaults to Client.Timeout, if set, or TokenFetchTimeout
+	FetchTimeout time.Duration
+
+	once  sync.Once
+	cache *cache.Cache[string, *Token]
+}
+
+/*
+Token returns a cached token, or fetches a new one.
+*/
+func (c *ClientCredentials) Token(ctx context.Context) (*Token, error) {
+	c.once.Do(c.init)
+	// Cached token (refreshed in background, if needed)
+	if token, ok := c.cache.GetOrRefresh(c.TokenURL, c.load); ok {
+		return token, nil
+	}
+	// Wait for a token or context cancellation
+	type result struct {
+		token *Token
+		err   error
+	}
+	results := make(chan result, 1)
+	go func() {
+		token, err := c.cache.GetOrLoad(c.TokenURL, c.load)
+		results <- result{token, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-results:
+		return r.token, r.err
+	}
+}
+
+// init builds a tokens cache.
+func (c *ClientCredentials) init() {
+	refresh := c.RefreshAhead
+	if refresh <= 0 || refresh >= 1 {
+		refresh = 0.8
+	}
+	c.cache = cache.New(cache.Config[string, *Token]{
+		MaxEntries:   1,
+		RefreshAhead: refresh,
+		TTLFunc: func(key string, token *Token) time.Duration {
+			return time.Until(token.Expiry)
+		},
+	})
+}
+
+// load fetches a new token with a fetch timeout.
+// Fetch is shared between callers, so it isn't bound to a caller context.
+func (c *ClientCredentials) load(string) (*Token, error) {
+	timeout := c.FetchTimeout
+	if timeout <= 0 && c.Client != nil {
+		timeout = c.Client.Timeout
+	}
+	if timeout <= 0 {
+		timeout = TokenFetchTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return c.fetch(ctx)
+}
+
+// fetch requests a new token.
+func (c *ClientCredentials) fetch(ctx context.Context) (*Token, error) {
+	// Compose form
+	form := url.Values{}
+	for k, v := range c.Params {
+		form[k] = v
+	}
+	form.Set("grant_type", "client_credentials")
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	// Request token
+	token := &Token{}
+	err := Request(http.MethodPost, c.TokenURL).
+		Context(ctx).
+		Client(c.Client).
+		BasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret)).
+		Header("Content-Type", "application/x-www-form-urlencoded").
+		Header("Accept", "application/json").
+		Body(strings.NewReader(form.Encode())).
+		Do().
+		Success().
+		Unmarshal(token, "application/json").
+		Error()
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, ErrTokenMissing
+	}
+	// Calculate expiry. Tokens without expiration are cached for an hour
+	expires := time.Duration(token.ExpiresIn) * time.Second
+	if expires <= 0 {
+		expires = time.Hour
+	}
+	token.Expiry = time.Now().Add(expires)
+
+	return token, nil
+}
+
+/*
+Transport is a ClientMiddleware, which sets a bearer token to outgoing requests.
+*/
+func (c *ClientCredentials) Transport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		token, err := c.Token(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+		return next.RoundTrip(req)
+	})
+}
@@ -0,0 +1,125 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenTestServer issues numbered tokens with a given lifetime.
+func tokenTestServer(expires int, delay time.Duration) (*httptest.Server, *int32) {
+	fetches := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(fetches, 1)
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":%d}`, n, expires)
+	}))
+
+	return server, fetches
+}
+
+func TestClientCredentialsSingleFetch(t *testing.T) {
+	server, fetches := tokenTestServer(3600, 20*time.Millisecond)
+	defer server.Close()
+
+	source := &ClientCredentials{TokenURL: server.URL}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := source.Token(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(fetches); n != 1 {
+		t.Fatalf("expected 1 fetch, got %d", n)
+	}
+}
+
+func TestClientCredentialsShortExpiry(t *testing.T) {
+	server, fetches := tokenTestServer(2, 0)
+	defer server.Close()
+
+	source := &ClientCredentials{TokenURL: server.URL}
+	for i := 0; i < 10; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "token-1" {
+			t.Fatalf("expected cached token-1, got %s", token.AccessToken)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(fetches); n != 1 {
+		t.Fatalf("expected short-lived token not to be refreshed on every call, got %d fetches", n)
+	}
+}
+
+func TestClientCredentialsRefreshAhead(t *testing.T) {
+	server, fetches := tokenTestServer(1, 0)
+	defer server.Close()
+
+	source := &ClientCredentials{TokenURL: server.URL, RefreshAhead: 0.1}
+	if _, err := source.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	// Still valid token is returned, while a new one is fetched
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "token-1" {
+		t.Fatalf("expected token-1 during refresh, got %s", token.AccessToken)
+	}
+	time.Sleep(50 * time.Millisecond)
+	token, _ = source.Token(context.Background())
+	if token.AccessToken != "token-2" || atomic.LoadInt32(fetches) != 2 {
+		t.Fatalf("expected refreshed token-2, got %s", token.AccessToken)
+	}
+}
+
+func TestClientCredentialsCanceled(t *testing.T) {
+	server, _ := tokenTestServer(3600, 100*time.Millisecond)
+	defer server.Close()
+
+	source := &ClientCredentials{TokenURL: server.URL}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := source.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	// Shared fetch is not canceled
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "token-1" {
+		t.Fatalf("expected token-1, got %s", token.AccessToken)
+	}
+}
+
+func TestClientCredentialsFetchTimeout(t *testing.T) {
+	server, _ := tokenTestServer(3600, 200*time.Millisecond)
+	defer server.Close()
+
+	source := &ClientCredentials{TokenURL: server.URL, FetchTimeout: 20 * time.Millisecond}
+	start := time.Now()
+	if _, err := source.Token(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("expected fetch to be limited with timeout, took %s", elapsed)
+	}
+}
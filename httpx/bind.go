@@ -0,0 +1,162 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var ErrBindTarget = errors.New("failed to bind request to struct, non struct pointer is given")
+
+// BindMaxMemory is a memory limit for multipart form parsing.
+var BindMaxMemory int64 = 32 << 20
+
+// pathParamsKey is a context key for path parameters.
+type pathParamsKey struct{}
+
+/*
+WithPathParams returns a shallow copy of request
+with path parameters stored in the context.
+Used by Router, but might be used with any other router
+to make parameters available for Bind.
+*/
+func WithPathParams(r *http.Request, params Params) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), pathParamsKey{}, params))
+}
+
+/*
+PathParams returns path parameters stored in the request context.
+Returns nil if there are no parameters.
+*/
+func PathParams(r *http.Request) Params {
+	params, _ := r.Context().Value(pathParamsKey{}).(Params)
+
+	return params
+}
+
+/*
+Bind fills a given struct pointer from request data
+and validates the result with Validate.
+Each data source has its own struct tag:
+
+  - json - request body, if content type is "application/json".
+  - form - urlencoded or multipart form body.
+  - query - url query.
+  - header - request headers (canonical or lower-case names).
+  - cookie - request cookies.
+  - path - path parameters, stored with WithPathParams (Router does it for you).
+
+Sources are applied in the order above, so later ones win.
+All tags, except json, are following Query tag format.
+Decoding failures are returned as is (usually *FieldError),
+validation failures are returned as ValidationErrors.
+
+Usage:
+
+	type Input struct {
+		ID      int    `path:"id"`
+		Page    int    `query:"page,default=1" validate:"min=1"`
+		Token   string `header:"X-Token" validate:"required"`
+		Name    string `json:"name" validate:"required,max=64"`
+	}
+
+	var input Input
+	if err := httpx.Bind(r, &input); err != nil {
+		...
+	}
+*/
+func Bind(r *http.Request, target any) error {
+	// Validate target is a struct pointer
+	ob := reflect.ValueOf(target)
+	if ob.Kind() != reflect.Ptr || ob.IsNil() || ob.Elem().Kind() != reflect.Struct {
+		return ErrBindTarget
+	}
+	ob = ob.Elem()
+	// Bind body
+	if err := bindBody(r, target, ob); err != nil {
+		return err
+	}
+	// Bind other sources
+	sources := []struct {
+		tag    string
+		values map[string][]string
+	}{
+		{"query", r.URL.Query()},
+		{"header", bindHeaderValues(r.Header)},
+		{"cookie", bindCookieValues(r.Cookies())},
+		{"path", bindPathValues(PathParams(r))},
+	}
+	for _, source := range sources {
+		if err := codecDecodeStruct(source.values, source.tag, "", ob); err != nil {
+			return err
+		}
+	}
+	// Validate result
+	return Validate(target)
+}
+
+// bindBody decodes json or form request body, depending on content type.
+func bindBody(r *http.Request, target any, ob reflect.Value) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	// Parse content type
+	mimetype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	// Decode body
+	switch {
+	case mimetype == "application/json" || strings.HasSuffix(mimetype, "+json"):
+		if err := json.NewDecoder(r.Body).Decode(target); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	case mimetype == "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return err
+		}
+
+		return codecDecodeStruct(r.PostForm, "form", "", ob)
+	case mimetype == "multipart/form-data":
+		if err := r.ParseMultipartForm(BindMaxMemory); err != nil {
+			return err
+		}
+
+		return codecDecodeStruct(r.MultipartForm.Value, "form", "", ob)
+	}
+
+	return nil
+}
+
+// bindHeaderValues duplicates header values under lower-case keys.
+func bindHeaderValues(header http.Header) map[string][]string {
+	values := make(map[string][]string, len(header)*2)
+	for k, v := range header {
+		values[k] = v
+		values[strings.ToLower(k)] = v
+	}
+
+	return values
+}
+
+// bindCookieValues converts cookies to a values map.
+func bindCookieValues(cookies []*http.Cookie) map[string][]string {
+	values := make(map[string][]string, len(cookies))
+	for _, cookie := range cookies {
+		values[cookie.Name] = append(values[cookie.Name], cookie.Value)
+	}
+
+	return values
+}
+
+// bindPathValues converts path parameters to a values map.
+func bindPathValues(params Params) map[string][]string {
+	values := make(map[string][]string, len(params))
+	for k, v := range params {
+		values[k] = []string{v}
+	}
+
+	return values
+}
@@ -0,0 +1,32 @@
+package httpx
+
+import (
+	"io"
+	"sync"
+)
+
+// bodyCloser calls a hook once on body close
+// (or on read error/EOF, if eof is set).
+type bodyCloser struct {
+	io.ReadCloser
+
+	onclose func()
+	eof     bool
+	once    sync.Once
+}
+
+func (b *bodyCloser) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && b.eof {
+		b.once.Do(b.onclose)
+	}
+
+	return n, err
+}
+
+func (b *bodyCloser) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.onclose)
+
+	return err
+}
@@ -0,0 +1,348 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBreakerOpen   = errors.New("circuit breaker is open")
+	ErrBreakerStatus = errors.New("upstream responded with server error")
+)
+
+/*
+BreakerOpenError is returned when circuit breaker rejects a call.
+It matches ErrBreakerOpen with errors.Is
+and is mapped to 503 by ErrorStatus/WriteError.
+*/
+type BreakerOpenError struct {
+	// RetryAfter is an approximate time until the next probe is allowed
+	RetryAfter time.Duration
+}
+
+func (e *BreakerOpenError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrBreakerOpen, e.RetryAfter)
+}
+
+func (e *BreakerOpenError) Is(target error) bool {
+	return target == ErrBreakerOpen //nolint:errorlint,goerr113
+}
+
+/*
+HTTPStatus returns 503 Service Unavailable.
+*/
+func (e *BreakerOpenError) HTTPStatus() int {
+	return http.StatusServiceUnavailable
+}
+
+/*
+BreakerState is a circuit breaker state.
+*/
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+/*
+Breaker is a circuit breaker.
+While closed, it counts calls and failures within a fixed Window.
+When failure rate reaches FailureRate (with at least MinRequests calls),
+breaker opens and rejects all calls with *BreakerOpenError for a Cooldown duration.
+After cooldown, breaker becomes half-open and lets HalfOpenRequests probe calls through.
+If all probes succeed, breaker closes, otherwise opens again.
+Probes without result within ProbeTimeout are abandoned, and new probes are allowed.
+Canceled calls (context.Canceled) are neutral:
+they are not counted and release their probe slot.
+
+Breaker might be used as a ClientMiddleware (Transport method),
+where transport errors and 5xx responses are counted as failures,
+or as a generic function wrapper with WithBreaker.
+
+Usage:
+
+	breaker := httpx.NewBreaker(0.5, 30 * time.Second)
+	client := httpx.Client(breaker.Transport)
+	// or
+	fetch := httpx.WithBreaker(breaker, func(ctx context.Context) (string, error) {
+		...
+	})
+*/
+type Breaker struct {
+	// FailureRate is a failure ratio (0..1] to open the breaker.
+	// Out of range values mean 0.5
+	FailureRate float64
+	// MinRequests is a minimal number of calls within window to evaluate failure rate
+	MinRequests int
+	// Window is a duration of calls counting window
+	Window time.Duration
+	// Cooldown is a duration of open state
+	Cooldown time.Duration
+	// HalfOpenRequests is a number of probe calls in half-open state
+	HalfOpenRequests int
+	// ProbeTimeout is a maximum duration of waiting for probe results
+	// in half-open state (Cooldown by default)
+	ProbeTimeout time.Duration
+	// IsFailure decides whether call error is a failure.
+	// By default, all errors are failures.
+	// It's not called for canceled calls
+	IsFailure func(error) bool
+	// OnStateChange is called on breaker state transitions (under lock, keep it fast)
+	OnStateChange func(from, to BreakerState)
+
+	lock       sync.Mutex
+	state      BreakerState
+	generation int
+	requests   int
+	failures   int
+	probes     int
+	successes  int
+	windowed   time.Time
+	opened     time.Time
+}
+
+/*
+State returns current breaker state.
+*/
+func (b *Breaker) State() BreakerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refresh(time.Now())
+
+	return b.state
+}
+
+/*
+Allow checks whether a call is allowed.
+If allowed, returned done function must be called with a call result.
+If not, *BreakerOpenError is returned.
+*/
+func (b *Breaker) Allow() (func(err error), error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	b.refresh(now)
+	// Check state
+	switch b.state {
+	case BreakerOpen:
+		return nil, &BreakerOpenError{RetryAfter: b.Cooldown - now.Sub(b.opened)}
+	case BreakerHalfOpen:
+		if b.probes >= b.halfOpenRequests() {
+			return nil, &BreakerOpenError{RetryAfter: b.Cooldown}
+		}
+		b.probes++
+	case BreakerClosed:
+		b.requests++
+	}
+	// Return result handler
+	generation := b.generation
+
+	return func(err error) {
+		b.done(generation, err)
+	}, nil
+}
+
+// done records a call result.
+func (b *Breaker) done(generation int, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// Ignore results from previous states
+	if generation != b.generation {
+		return
+	}
+	// Canceled calls are neutral
+	if errors.Is(err, context.Canceled) {
+		switch b.state {
+		case BreakerClosed:
+			// Might be already reset by window
+			if b.requests > 0 {
+				b.requests--
+			}
+		case BreakerHalfOpen:
+			b.probes--
+		case BreakerOpen:
+		}
+		return
+	}
+
+	failure := b.isFailure(err)
+	switch b.state {
+	case BreakerClosed:
+		if failure {
+			b.failures++
+		}
+		if b.failures > 0 && b.requests >= b.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= b.failureRate() {
+			b.transition(BreakerOpen, time.Now())
+		}
+	case BreakerHalfOpen:
+		if failure {
+			b.transition(BreakerOpen, time.Now())
+			return
+		}
+		b.successes++
+		if b.successes >= b.halfOpenRequests() {
+			b.transition(BreakerClosed, time.Now())
+		}
+	case BreakerOpen:
+	}
+}
+
+// refresh handles time-based transitions.
+func (b *Breaker) refresh(now time.Time) {
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.opened) >= b.Cooldown {
+			b.transition(BreakerHalfOpen, now)
+		}
+	case BreakerClosed:
+		if b.Window > 0 && now.Sub(b.windowed) >= b.Window {
+			b.requests, b.failures, b.windowed = 0, 0, now
+		}
+	case BreakerHalfOpen:
+		// Abandon lost probes (done is never called), starting over
+		if b.probes > b.successes && now.Sub(b.windowed) >= b.probeTimeout() {
+			b.transition(BreakerHalfOpen, now)
+		}
+	}
+}
+
+// transition switches breaker state and resets counters.
+func (b *Breaker) transition(state BreakerState, now time.Time) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.requests, b.failures, b.probes, b.successes = 0, 0, 0, 0
+	b.windowed = now
+	if state == BreakerOpen {
+		b.opened = now
+	}
+	if b.OnStateChange != nil && from != state {
+		b.OnStateChange(from, state)
+	}
+}
+
+// isFailure checks whether error is a failure.
+func (b *Breaker) isFailure(err error) bool {
+	if b.IsFailure != nil {
+		return b.IsFailure(err)
+	}
+
+	return err != nil
+}
+
+// failureRate returns failure ratio, 0.5 if out of range.
+func (b *Breaker) failureRate() float64 {
+	if b.FailureRate <= 0 || b.FailureRate > 1 {
+		return 0.5
+	}
+
+	return b.FailureRate
+}
+
+// probeTimeout returns probe results waiting duration.
+func (b *Breaker) probeTimeout() time.Duration {
+	if b.ProbeTimeout <= 0 {
+		return b.Cooldown
+	}
+
+	return b.ProbeTimeout
+}
+
+// halfOpenRequests returns probes number, at least 1.
+func (b *Breaker) halfOpenRequests() int {
+	if b.HalfOpenRequests < 1 {
+		return 1
+	}
+
+	return b.HalfOpenRequests
+}
+
+/*
+Transport is a ClientMiddleware.
+Transport errors and 5xx responses are counted as failures.
+*/
+func (b *Breaker) Transport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		done, err := b.Allow()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next.RoundTrip(req)
+		switch {
+		case err != nil:
+			done(err)
+		case resp.StatusCode >= 500:
+			done(ErrorWithStatus(resp.StatusCode, fmt.Errorf("%w: %d", ErrBreakerStatus, resp.StatusCode)))
+		default:
+			done(nil)
+		}
+
+		return resp, err
+	})
+}
+
+/*
+WithBreaker wraps a function with a circuit breaker.
+
+Usage:
+
+	fetch := httpx.WithBreaker(breaker, func(ctx context.Context) (string, error) {
+		...
+	})
+	val, err := fetch(ctx)
+	if errors.Is(err, httpx.ErrBreakerOpen) {
+		...
+	}
+*/
+func WithBreaker[T any](b *Breaker, fn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		done, err := b.Allow()
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		val, err := fn(ctx)
+		done(err)
+
+		return val, err
+	}
+}
+
+/*
+NewBreaker is a Breaker constructor with defaults:
+10 minimal requests, 1 minute window, 1 half-open probe.
+Check Breaker for details.
+*/
+func NewBreaker(failurerate float64, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		FailureRate:      failurerate,
+		MinRequests:      10,
+		Window:           time.Minute,
+		Cooldown:         cooldown,
+		HalfOpenRequests: 1,
+		windowed:         time.Now(),
+	}
+}
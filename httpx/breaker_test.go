@@ -0,0 +1,97 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpens(t *testing.T) {
+	breaker := NewBreaker(0.5, time.Minute)
+	breaker.MinRequests = 2
+	for i := 0; i < 2; i++ {
+		done, err := breaker.Allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		done(errors.New("failed"))
+	}
+	if _, err := breaker.Allow(); !errors.Is(err, ErrBreakerOpen) {
+		t.Fatalf("expected ErrBreakerOpen, got %v", err)
+	}
+}
+
+func TestBreakerCanceledIsNeutral(t *testing.T) {
+	breaker := NewBreaker(0.5, 10*time.Millisecond)
+	breaker.MinRequests = 1
+	// Canceled calls don't open the breaker
+	for i := 0; i < 5; i++ {
+		done, err := breaker.Allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		done(context.Canceled)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("expected closed breaker, got %s", breaker.State())
+	}
+	// Open, wait for half-open
+	done, _ := breaker.Allow()
+	done(errors.New("failed"))
+	time.Sleep(15 * time.Millisecond)
+	if breaker.State() != BreakerHalfOpen {
+		t.Fatalf("expected half-open breaker, got %s", breaker.State())
+	}
+	// Canceled probe releases its slot
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done(context.Canceled)
+	done, err = breaker.Allow()
+	if err != nil {
+		t.Fatalf("expected probe slot to be released, got %v", err)
+	}
+	done(nil)
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("expected closed breaker, got %s", breaker.State())
+	}
+}
+
+func TestBreakerLostProbe(t *testing.T) {
+	breaker := NewBreaker(0.5, 10*time.Millisecond)
+	breaker.MinRequests = 1
+	breaker.ProbeTimeout = 10 * time.Millisecond
+	done, _ := breaker.Allow()
+	done(errors.New("failed"))
+	time.Sleep(15 * time.Millisecond)
+	// Probe, which never reports
+	if _, err := breaker.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := breaker.Allow(); !errors.Is(err, ErrBreakerOpen) {
+		t.Fatalf("expected probe slot to be taken, got %v", err)
+	}
+	time.Sleep(15 * time.Millisecond)
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("expected lost probe to be abandoned, got %v", err)
+	}
+	done(nil)
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("expected closed breaker, got %s", breaker.State())
+	}
+}
+
+func TestBreakerSuccessesOnly(t *testing.T) {
+	for _, breaker := range []*Breaker{{}, NewBreaker(0, time.Minute)} {
+		for i := 0; i < 20; i++ {
+			done, err := breaker.Allow()
+			if err != nil {
+				t.Fatalf("expected successes only never to open the breaker, got %v", err)
+			}
+			done(nil)
+		}
+	}
+}
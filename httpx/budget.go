@@ -0,0 +1,89 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// BudgetHeader is a header, used to pass remaining deadline budget (in milliseconds) downstream.
+var BudgetHeader = "X-Request-Budget"
+
+/*
+Budget sets a deadline budget for the request.
+Request execution (Do), including retries and hedged requests, is limited with a given timeout
+(in addition to the context deadline, the earliest wins),
+and the remaining time is passed downstream with BudgetHeader
+(recomputed for each attempt and hedged request).
+Zero timeout only passes the context deadline, if any.
+
+Usage:
+
+	// In a handler, wrapped with DeadlineBudget
+	httpx.Request("GET", "https://inventory/items").
+		Context(r.Context()).
+		Budget(500*time.Millisecond).
+		Do()
+*/
+func (r *RequestBuilder) Budget(timeout time.Duration) *RequestBuilder {
+	r.budget = timeout
+	r.budgeted = true
+
+	return r
+}
+
+// budgetContext applies budget timeout to builder context.
+func (r *RequestBuilder) budgetContext() (context.Context, context.CancelFunc) {
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if r.budget <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, r.budget)
+}
+
+// budgetHeader passes remaining budget to request header.
+func (r *RequestBuilder) budgetHeader(request *http.Request) {
+	if !r.budgeted {
+		return
+	}
+	deadline, ok := request.Context().Deadline()
+	if r.budget > 0 && (!ok || time.Now().Add(r.budget).Before(deadline)) {
+		deadline, ok = time.Now().Add(r.budget), true
+	}
+	if !ok {
+		return
+	}
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining < 0 {
+		remaining = 0
+	}
+	request.Header.Set(BudgetHeader, strconv.FormatInt(remaining, 10))
+}
+
+/*
+DeadlineBudget is a server middleware, which limits request context
+with a deadline budget, received with BudgetHeader.
+Downstream requests with Budget and request context
+will pass the remaining budget further.
+
+Usage:
+
+	router.Use(httpx.DeadlineBudget)
+*/
+func DeadlineBudget(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		millis, err := strconv.ParseInt(r.Header.Get(BudgetHeader), 10, 64)
+		if err != nil || millis < 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), time.Duration(millis)*time.Millisecond)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,140 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/*
+ErrBulkheadFull is returned when bulkhead has no free slots
+within a wait time. It's mapped to 503 by ErrorStatus/WriteError.
+*/
+var ErrBulkheadFull = ErrorWithStatus(http.StatusServiceUnavailable, errors.New("bulkhead is full"))
+
+// BulkheadMax is a default maximum number of concurrent calls per key.
+var BulkheadMax = 10
+
+/*
+Bulkhead limits a number of concurrent calls per key (host for Transport).
+If all slots are taken, call waits for a free slot up to Wait duration
+(or context cancellation) and fails with ErrBulkheadFull.
+Zero Wait means failing immediately.
+
+Usage:
+
+	bulkhead := httpx.NewBulkhead(10, 100 * time.Millisecond)
+	client := httpx.Client(bulkhead.Transport)
+	// or
+	fetch := httpx.WithBulkhead(bulkhead, "db", func(ctx context.Context) (string, error) {
+		...
+	})
+*/
+type Bulkhead struct {
+	// Max is a maximum number of concurrent calls per key (BulkheadMax by default)
+	Max int
+	// Wait is a maximum duration of waiting for a free slot
+	Wait time.Duration
+
+	lock  sync.Mutex
+	slots map[string]chan struct{}
+}
+
+/*
+Acquire takes a slot for a given key.
+Returned release function must be called on call completion.
+*/
+func (b *Bulkhead) Acquire(ctx context.Context, key string) (func(), error) {
+	slots := b.semaphore(key)
+	release := func() { <-slots }
+	// Try to acquire immediately
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if b.Wait <= 0 {
+		return nil, ErrBulkheadFull
+	}
+	// Wait for a free slot
+	timer := time.NewTimer(b.Wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, ErrBulkheadFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// semaphore returns a slots channel for a key.
+func (b *Bulkhead) semaphore(key string) chan struct{} {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.slots == nil {
+		b.slots = map[string]chan struct{}{}
+	}
+	if b.slots[key] == nil {
+		size := b.Max
+		if size <= 0 {
+			size = BulkheadMax
+		}
+		b.slots[key] = make(chan struct{}, size)
+	}
+
+	return b.slots[key]
+}
+
+/*
+Transport is a ClientMiddleware, limiting concurrent requests per host.
+Slot is released on response body close.
+*/
+func (b *Bulkhead) Transport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		release, err := b.Acquire(req.Context(), req.URL.Host)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		resp.Body = &bodyCloser{ReadCloser: resp.Body, onclose: release}
+
+		return resp, nil
+	})
+}
+
+/*
+WithBulkhead wraps a function with a bulkhead under a given key.
+*/
+func WithBulkhead[T any](b *Bulkhead, key string, fn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		release, err := b.Acquire(ctx, key)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		defer release()
+
+		return fn(ctx)
+	}
+}
+
+/*
+NewBulkhead is a Bulkhead constructor.
+Non-positive max means BulkheadMax.
+Check Bulkhead for details.
+*/
+func NewBulkhead(max int, wait time.Duration) *Bulkhead {
+	return &Bulkhead{
+		Max:  max,
+		Wait: wait,
+	}
+}
@@ -0,0 +1,26 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBulkheadDefaultMax(t *testing.T) {
+	bulkhead := NewBulkhead(0, 0)
+	releases := []func(){}
+	for i := 0; i < BulkheadMax; i++ {
+		release, err := bulkhead.Acquire(context.Background(), "key")
+		if err != nil {
+			t.Fatalf("expected slot %d to be acquired, got %v", i, err)
+		}
+		releases = append(releases, release)
+	}
+	if _, err := bulkhead.Acquire(context.Background(), "key"); !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull, got %v", err)
+	}
+	releases[0]()
+	if _, err := bulkhead.Acquire(context.Background(), "key"); err != nil {
+		t.Fatalf("expected released slot to be acquired, got %v", err)
+	}
+}
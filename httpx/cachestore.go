@@ -0,0 +1,155 @@
+package httpx
+
+import (
+	"time"
+
+	"github.com/yznts/zen/v3/cache"
+)
+
+// CacheStoreMaxSize is a default size limit of cache stores, in bytes.
+var CacheStoreMaxSize int64 = 64 << 20
+
+/*
+CacheStore is a storage backend for CacheTransport.
+Implementations must be safe for concurrent use.
+*/
+type CacheStore interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+/*
+CacheStoreConfig is a built-in cache stores configuration.
+All fields are optional.
+*/
+type CacheStoreConfig struct {
+	// MaxSize limits total size of stored responses in bytes,
+	// least recently used ones are evicted on overflow.
+	// CacheStoreMaxSize by default, negative means no limit
+	MaxSize int64
+	// TTL limits how long responses are kept, regardless of their freshness
+	// (stale responses are still useful for revalidation).
+	// Zero means no limit
+	TTL time.Duration
+}
+
+// maxsize returns size limit with default applied, zero means no limit.
+func (c CacheStoreConfig) maxsize() int64 {
+	switch {
+	case c.MaxSize < 0:
+		return 0
+	case c.MaxSize == 0:
+		return CacheStoreMaxSize
+	default:
+		return c.MaxSize
+	}
+}
+
+/*
+MemoryCacheStore is an in-memory CacheStore, built on cache.Cache.
+Responses are evicted in LRU order to fit size limit.
+*/
+type MemoryCacheStore struct {
+	cache *cache.Cache[string, []byte]
+}
+
+// Get returns a stored value.
+func (s *MemoryCacheStore) Get(key string) ([]byte, bool) {
+	return s.cache.Get(key)
+}
+
+// Set stores a value.
+func (s *MemoryCacheStore) Set(key string, value []byte) {
+	s.cache.Set(key, value)
+}
+
+// Delete removes a value.
+func (s *MemoryCacheStore) Delete(key string) {
+	s.cache.Delete(key)
+}
+
+/*
+Stats returns store statistics.
+*/
+func (s *MemoryCacheStore) Stats() cache.Stats {
+	return s.cache.Stats()
+}
+
+/*
+NewMemoryCacheStore is a MemoryCacheStore constructor.
+Check CacheStoreConfig for defaults.
+
+Usage:
+
+	store := httpx.NewMemoryCacheStore(httpx.CacheStoreConfig{MaxSize: 16 << 20})
+*/
+func NewMemoryCacheStore(config ...CacheStoreConfig) *MemoryCacheStore {
+	if len(config) == 0 {
+		config = append(config, CacheStoreConfig{})
+	}
+
+	return &MemoryCacheStore{
+		cache: cache.New(cache.Config[string, []byte]{
+			TTL:     config[0].TTL,
+			MaxCost: config[0].maxsize(),
+			Cost: func(key string, value []byte) int64 {
+				return int64(len(key) + len(value))
+			},
+		}),
+	}
+}
+
+/*
+DiskCacheStore is an on-disk CacheStore, built on cache.DiskStore.
+Each value is stored in a separate file, named with a key hash.
+Responses are evicted in LRU order to fit size limit.
+Errors are ignored, store acts as a miss in that case.
+*/
+type DiskCacheStore struct {
+	store *cache.DiskStore[string, []byte]
+}
+
+// Get returns a stored value.
+func (s *DiskCacheStore) Get(key string) ([]byte, bool) {
+	return s.store.Get(key)
+}
+
+// Set stores a value.
+func (s *DiskCacheStore) Set(key string, value []byte) {
+	s.store.Set(key, value) //nolint:errcheck
+}
+
+// Delete removes a value.
+func (s *DiskCacheStore) Delete(key string) {
+	s.store.Delete(key) //nolint:errcheck
+}
+
+/*
+Stats returns store statistics.
+*/
+func (s *DiskCacheStore) Stats() cache.Stats {
+	return s.store.Stats()
+}
+
+/*
+NewDiskCacheStore is a DiskCacheStore constructor.
+Creates a directory, if not exists, and indexes existing entries.
+Check CacheStoreConfig for defaults.
+*/
+func NewDiskCacheStore(dir string, config ...CacheStoreConfig) (*DiskCacheStore, error) {
+	if len(config) == 0 {
+		config = append(config, CacheStoreConfig{})
+	}
+	store, err := cache.NewDiskStore[string, []byte](cache.DiskConfig{
+		Dir:     dir,
+		Codec:   cache.GobCodec{},
+		TTL:     config[0].TTL,
+		MaxSize: config[0].maxsize(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DiskCacheStore{store: store}, nil
+}
@@ -0,0 +1,537 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yznts/zen/v3/slice"
+)
+
+// CacheHeuristicLimit limits heuristic freshness lifetime, based on Last-Modified.
+var CacheHeuristicLimit = 24 * time.Hour
+
+// CacheMaxBodySize is a default response body size limit for CacheTransport, in bytes.
+var CacheMaxBodySize int64 = 10 << 20
+
+// cacheStatuses lists response statuses, cacheable by default.
+var cacheStatuses = map[int]bool{
+	200: true, 203: true, 204: true, 300: true, 301: true, 308: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+/*
+CacheTransport is an opt-in caching http.RoundTripper,
+acting as a private (client-side) HTTP cache.
+It honours Cache-Control (max-age, no-cache, no-store, stale-while-revalidate),
+Expires, ETag/If-None-Match, Last-Modified/If-Modified-Since and Vary
+(a response variant is stored per values of headers, listed in Vary).
+Only GET and HEAD responses are cached,
+successful unsafe requests invalidate cached responses for the same url.
+Stale responses within stale-while-revalidate window are served immediately,
+while revalidation is done in background.
+Responses are marked with "X-Cache" header (HIT, MISS, STALE or REVALIDATED).
+Response bodies are not buffered: a body is copied into the store
+while the caller reads it, and the response is stored
+only when the body is read till the end.
+
+Usage:
+
+	client := httpx.Client(httpx.Cache(httpx.NewMemoryCacheStore()))
+	// or
+	client := &http.Client{Transport: &httpx.CacheTransport{Store: store}}
+*/
+type CacheTransport struct {
+	// Transport is used to make actual requests. Defaults to http.DefaultTransport
+	Transport http.RoundTripper
+	// Store holds cached responses
+	Store CacheStore
+	// MaxBodySize limits size of cached response bodies,
+	// larger responses are passed through without caching.
+	// CacheMaxBodySize by default
+	MaxBodySize int64
+
+	revalidating sync.Map
+	vary         sync.Mutex // serializes variants index updates
+}
+
+// cacheEntry is a serialized cached response.
+// Responses with Vary are stored under secondary (variant) keys,
+// while a primary key holds an index entry with VaryBy and Variants only.
+type cacheEntry struct {
+	Stored   time.Time         `json:"stored"`
+	Vary     map[string]string `json:"vary,omitempty"`
+	VaryBy   []string          `json:"varyby,omitempty"`
+	Variants []string          `json:"variants,omitempty"`
+	Status   int               `json:"status"`
+	Header   http.Header       `json:"header"`
+	Body     []byte            `json:"body,omitempty"`
+}
+
+// cacheBody passes response body to a caller,
+// copying it into a buffer, which is stored on EOF.
+type cacheBody struct {
+	body   io.ReadCloser
+	buffer bytes.Buffer
+	limit  int64
+	store  func(body []byte) // nil after storing or overflow
+	drop   func()            // called on overflow
+}
+
+func (b *cacheBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if b.store != nil {
+		// Too large, stop copying
+		if int64(b.buffer.Len()+n) > b.limit {
+			b.store = nil
+			b.buffer = bytes.Buffer{}
+			b.drop()
+		} else {
+			b.buffer.Write(p[:n])
+		}
+	}
+	if errors.Is(err, io.EOF) && b.store != nil {
+		b.store(b.buffer.Bytes())
+		b.store = nil
+	}
+
+	return n, err
+}
+
+func (b *cacheBody) Close() error {
+	return b.body.Close()
+}
+
+/*
+RoundTrip implements http.RoundTripper.
+*/
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Unsafe methods are invalidating cache
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := t.transport().RoundTrip(req)
+		if err == nil && resp.StatusCode < 400 {
+			t.delete(cacheKey(http.MethodGet, req))
+			t.delete(cacheKey(http.MethodHead, req))
+		}
+
+		return resp, err
+	}
+	// Requests, which are bypassing cache
+	reqcc := cacheControl(req.Header)
+	if _, nostore := reqcc["no-store"]; nostore || req.Header.Get("Range") != "" {
+		return t.transport().RoundTrip(req)
+	}
+	// Lookup
+	key := cacheKey(req.Method, req)
+	entry, ok := t.load(key, req)
+	if !ok {
+		return t.fetch(key, req)
+	}
+	resp, err := entry.response(req)
+	if err != nil {
+		t.delete(key)
+		return t.fetch(key, req)
+	}
+	// Check freshness
+	var (
+		respcc   = cacheControl(resp.Header)
+		age      = entry.age(resp.Header)
+		lifetime = cacheLifetime(resp.Header, entry.Stored)
+	)
+	_, reqnocache := reqcc["no-cache"]
+	_, respnocache := respcc["no-cache"]
+	if maxage, ok := cacheSeconds(reqcc, "max-age"); ok && maxage < lifetime {
+		lifetime = maxage
+	}
+	if !reqnocache && !respnocache {
+		// Fresh response
+		if age < lifetime {
+			resp.Header.Set("X-Cache", "HIT")
+			return resp, nil
+		}
+		// Stale response, which might be served while revalidating
+		if swr, ok := cacheSeconds(respcc, "stale-while-revalidate"); ok && age < lifetime+swr {
+			t.revalidateAsync(key, req, entry)
+			resp.Header.Set("X-Cache", "STALE")
+			return resp, nil
+		}
+	}
+	// Revalidate
+	return t.revalidate(key, req, entry)
+}
+
+// transport returns underlying transport.
+func (t *CacheTransport) transport() http.RoundTripper {
+	if t.Transport == nil {
+		return http.DefaultTransport
+	}
+
+	return t.Transport
+}
+
+// fetch executes request and stores the response, if cacheable.
+func (t *CacheTransport) fetch(key string, req *http.Request) (*http.Response, error) {
+	resp, err := t.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.save(key, req, resp)
+	resp.Header.Set("X-Cache", "MISS")
+
+	return resp, nil
+}
+
+// revalidate executes conditional request and updates cached entry.
+func (t *CacheTransport) revalidate(key string, req *http.Request, entry *cacheEntry) (*http.Response, error) {
+	stored, err := entry.response(req)
+	if err != nil {
+		return t.fetch(key, req)
+	}
+	// Compose conditional request
+	creq := req.Clone(req.Context())
+	if etag := stored.Header.Get("ETag"); etag != "" {
+		creq.Header.Set("If-None-Match", etag)
+	}
+	if modified := stored.Header.Get("Last-Modified"); modified != "" {
+		creq.Header.Set("If-Modified-Since", modified)
+	}
+	// Execute
+	resp, err := t.transport().RoundTrip(creq)
+	if err != nil {
+		return nil, err
+	}
+	// Not modified, update stored headers
+	if resp.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, resp.Body) //nolint:errcheck
+		resp.Body.Close()
+		for k, v := range resp.Header {
+			if k == "Content-Length" {
+				continue
+			}
+			stored.Header[k] = v
+		}
+		stored.Header.Del("Age")
+		if cacheable(stored) {
+			t.store(key, req, stored.StatusCode, stored.Header, time.Now(), entry.Body)
+		} else {
+			t.delete(key)
+		}
+		stored.Header.Set("X-Cache", "REVALIDATED")
+
+		return stored, nil
+	}
+	// Modified, store new response
+	t.save(key, req, resp)
+	resp.Header.Set("X-Cache", "MISS")
+
+	return resp, nil
+}
+
+// revalidateAsync runs background revalidation, one per key at a time.
+func (t *CacheTransport) revalidateAsync(key string, req *http.Request, entry *cacheEntry) {
+	if _, running := t.revalidating.LoadOrStore(key, true); running {
+		return
+	}
+	// Detach request from caller context
+	breq := req.Clone(context.Background())
+	go func() {
+		defer t.revalidating.Delete(key)
+		resp, err := t.revalidate(key, breq, entry)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck
+			resp.Body.Close()
+		}
+	}()
+}
+
+// load returns cached entry, if exists and matches Vary headers.
+func (t *CacheTransport) load(key string, req *http.Request) (*cacheEntry, bool) {
+	entry, ok := t.entry(key)
+	if !ok {
+		return nil, false
+	}
+	// Lookup a variant
+	if len(entry.VaryBy) > 0 {
+		if entry, ok = t.entry(cacheVaryKey(key, entry.VaryBy, req)); !ok {
+			return nil, false
+		}
+	}
+	for header, value := range entry.Vary {
+		if req.Header.Get(header) != value {
+			return nil, false
+		}
+	}
+
+	return entry, true
+}
+
+// entry reads and decodes a stored entry.
+func (t *CacheTransport) entry(key string) (*cacheEntry, bool) {
+	data, ok := t.Store.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// delete removes a stored entry with all its variants.
+func (t *CacheTransport) delete(key string) {
+	if entry, ok := t.entry(key); ok {
+		for _, variant := range entry.Variants {
+			t.Store.Delete(variant)
+		}
+	}
+	t.Store.Delete(key)
+}
+
+// save stores response, if cacheable, or removes stale entry otherwise.
+// Response body is wrapped to be stored, when caller reads it till the end.
+// Responses, larger than body size limit, are not stored.
+func (t *CacheTransport) save(key string, req *http.Request, resp *http.Response) {
+	if !cacheable(resp) {
+		t.delete(key)
+		return
+	}
+	var (
+		stored = time.Now()
+		status = resp.StatusCode
+		header = resp.Header.Clone()
+	)
+	// Responses without body are stored immediately
+	if req.Method == http.MethodHead || resp.Body == nil || resp.Body == http.NoBody {
+		t.store(key, req, status, header, stored, nil)
+		return
+	}
+	limit := t.MaxBodySize
+	if limit <= 0 {
+		limit = CacheMaxBodySize
+	}
+	if resp.ContentLength > limit {
+		t.delete(key)
+		return
+	}
+	resp.Body = &cacheBody{
+		body:  resp.Body,
+		limit: limit,
+		store: func(body []byte) {
+			t.store(key, req, status, header, stored, body)
+		},
+		drop: func() {
+			t.delete(key)
+		},
+	}
+}
+
+// store serializes and stores a response.
+// Responses with Vary are stored as variants, indexed under the primary key.
+func (t *CacheTransport) store(key string, req *http.Request, status int, header http.Header, stored time.Time, body []byte) {
+	entry := cacheEntry{
+		Stored: stored,
+		Vary:   map[string]string{},
+		Status: status,
+		Header: header.Clone(),
+		Body:   body,
+	}
+	entry.Header.Del("X-Cache")
+	varyby := []string{}
+	for _, header := range strings.Split(header.Get("Vary"), ",") {
+		if header = http.CanonicalHeaderKey(strings.TrimSpace(header)); header != "" {
+			entry.Vary[header] = req.Header.Get(header)
+			varyby = append(varyby, header)
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	// Update variants index
+	t.vary.Lock()
+	defer t.vary.Unlock()
+	index, ok := t.entry(key)
+	if len(varyby) == 0 {
+		if ok && len(index.Variants) > 0 {
+			t.delete(key)
+		}
+		t.Store.Set(key, data)
+		return
+	}
+	sort.Strings(varyby)
+	if !ok || strings.Join(index.VaryBy, ",") != strings.Join(varyby, ",") {
+		// Different Vary headers, drop old variants
+		if ok {
+			t.delete(key)
+		}
+		index = &cacheEntry{Stored: stored, VaryBy: varyby}
+	}
+	variant := cacheVaryKey(key, varyby, req)
+	if !slice.Contains(index.Variants, variant) {
+		index.Variants = append(index.Variants, variant)
+		indexdata, err := json.Marshal(index)
+		if err != nil {
+			return
+		}
+		t.Store.Set(key, indexdata)
+	}
+	t.Store.Set(variant, data)
+}
+
+// response restores cached response.
+func (e *cacheEntry) response(req *http.Request) (*http.Response, error) {
+	if e.Status == 0 {
+		return nil, errors.New("invalid cache entry")
+	}
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}, nil
+}
+
+// age calculates current response age.
+func (e *cacheEntry) age(header http.Header) time.Duration {
+	age := time.Since(e.Stored)
+	if seconds, err := strconv.Atoi(header.Get("Age")); err == nil {
+		age += time.Duration(seconds) * time.Second
+	}
+
+	return age
+}
+
+// cacheable checks whether response might be stored.
+func cacheable(resp *http.Response) bool {
+	if !cacheStatuses[resp.StatusCode] {
+		return false
+	}
+	directives := cacheControl(resp.Header)
+	if _, nostore := directives["no-store"]; nostore {
+		return false
+	}
+	if strings.TrimSpace(resp.Header.Get("Vary")) == "*" {
+		return false
+	}
+	_, maxage := cacheSeconds(directives, "max-age")
+
+	return maxage ||
+		resp.Header.Get("Expires") != "" ||
+		cacheLifetime(resp.Header, time.Now()) > 0 ||
+		resp.Header.Get("ETag") != "" ||
+		resp.Header.Get("Last-Modified") != ""
+}
+
+// cacheLifetime calculates response freshness lifetime.
+// Received time is used as a base, if response has no Date header.
+func cacheLifetime(header http.Header, received time.Time) time.Duration {
+	// Explicit max-age
+	if maxage, ok := cacheSeconds(cacheControl(header), "max-age"); ok {
+		return maxage
+	}
+	// Date, used as a base for other calculations
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = received
+	}
+	// Expires
+	if expires := header.Get("Expires"); expires != "" {
+		exp, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+
+		return exp.Sub(date)
+	}
+	// Heuristic, based on Last-Modified
+	if modified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		lifetime := date.Sub(modified) / 10
+		if lifetime > CacheHeuristicLimit {
+			lifetime = CacheHeuristicLimit
+		}
+
+		return lifetime
+	}
+
+	return 0
+}
+
+// cacheControl parses Cache-Control header directives.
+func cacheControl(header http.Header) map[string]string {
+	directives := map[string]string{}
+	for _, part := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+
+	return directives
+}
+
+// cacheSeconds parses directive value as seconds.
+func cacheSeconds(directives map[string]string, name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// cacheKey composes a cache key for a request.
+func cacheKey(method string, req *http.Request) string {
+	return method + " " + req.URL.String()
+}
+
+// cacheVaryKey composes a secondary cache key for a response variant,
+// selected with request headers, listed in Vary (sorted).
+func cacheVaryKey(key string, varyby []string, req *http.Request) string {
+	var builder strings.Builder
+	builder.WriteString(key)
+	for _, header := range varyby {
+		builder.WriteString("\n" + header + ": " + req.Header.Get(header))
+	}
+
+	return builder.String()
+}
+
+/*
+Cache is a ClientMiddleware, which wraps transport with CacheTransport.
+
+Usage:
+
+	client := httpx.Client(httpx.Cache(httpx.NewMemoryCacheStore()))
+*/
+func Cache(store CacheStore) ClientMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &CacheTransport{Transport: next, Store: store}
+	}
+}
@@ -0,0 +1,173 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheTransportStoresOnRead(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, "cached body")
+	}))
+	defer server.Close()
+	store := NewMemoryCacheStore()
+	client := Client(Cache(store))
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get("X-Cache") != "MISS" {
+		t.Fatalf("expected MISS, got %q", resp.Header.Get("X-Cache"))
+	}
+	// Nothing is stored until the body is read
+	if _, ok := store.Get(cacheKey(http.MethodGet, resp.Request)); ok {
+		t.Fatalf("expected response to be stored only after reading")
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "cached body" {
+		t.Fatalf("expected body, got %q", body)
+	}
+
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.Header.Get("X-Cache") != "HIT" || string(body) != "cached body" {
+		t.Fatalf("expected cached body HIT, got %q %q", resp.Header.Get("X-Cache"), body)
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestCacheTransportRevalidate(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "versioned body")
+	}))
+	defer server.Close()
+	client := Client(Cache(NewMemoryCacheStore()))
+
+	for i, expected := range []string{"MISS", "REVALIDATED", "REVALIDATED"} {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.Header.Get("X-Cache") != expected || string(body) != "versioned body" {
+			t.Fatalf("request %d: expected %s, got %q %q", i, expected, resp.Header.Get("X-Cache"), body)
+		}
+	}
+}
+
+func TestCacheTransportVary(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept")
+		fmt.Fprint(w, r.Header.Get("Accept"))
+	}))
+	defer server.Close()
+	client := Client(Cache(NewMemoryCacheStore()))
+
+	steps := []struct {
+		method string
+		accept string
+		cache  string
+	}{
+		{"GET", "text/plain", "MISS"},
+		{"GET", "application/json", "MISS"},
+		{"GET", "text/plain", "HIT"},
+		{"GET", "application/json", "HIT"},
+		{"POST", "text/plain", ""},
+		{"GET", "application/json", "MISS"},
+	}
+	for i, step := range steps {
+		req, _ := http.NewRequest(step.method, server.URL, nil)
+		req.Header.Set("Accept", step.accept)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.Header.Get("X-Cache") != step.cache || string(body) != step.accept {
+			t.Fatalf("step %d: expected %s %q, got %q %q", i, step.cache, step.accept, resp.Header.Get("X-Cache"), body)
+		}
+	}
+	if requests != 4 {
+		t.Fatalf("expected 4 requests, got %d", requests)
+	}
+}
+
+func TestCacheLifetimeExpiresWithoutDate(t *testing.T) {
+	received := time.Now().Add(-time.Hour)
+	header := http.Header{}
+	header.Set("Expires", received.Add(2*time.Hour).UTC().Format(http.TimeFormat))
+	// Lifetime is counted from received time, not from now
+	lifetime := cacheLifetime(header, received)
+	if lifetime < 2*time.Hour-time.Second || lifetime > 2*time.Hour {
+		t.Fatalf("expected 2h lifetime, got %s", lifetime)
+	}
+}
+
+func TestCacheTransportMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		// Chunked, without Content-Length
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, strings.Repeat("x", 1024))
+	}))
+	defer server.Close()
+	store := NewMemoryCacheStore()
+	client := &http.Client{Transport: &CacheTransport{Store: store, MaxBodySize: 100}}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if len(body) != 1024 || resp.Header.Get("X-Cache") != "MISS" {
+			t.Fatalf("expected full uncached body, got %d bytes, %q", len(body), resp.Header.Get("X-Cache"))
+		}
+	}
+}
+
+func TestDiskCacheStore(t *testing.T) {
+	store, err := NewDiskCacheStore(t.TempDir(), CacheStoreConfig{MaxSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("key", []byte("value"))
+	if value, ok := store.Get("key"); !ok || string(value) != "value" {
+		t.Fatalf("expected stored value, got %q, %v", value, ok)
+	}
+	store.Delete("key")
+	if _, ok := store.Get("key"); ok {
+		t.Fatalf("expected value to be deleted")
+	}
+}
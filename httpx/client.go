@@ -0,0 +1,57 @@
+package httpx
+
+import (
+	"net/http"
+)
+
+/*
+RoundTripperFunc is an adapter to use ordinary functions as http.RoundTripper.
+*/
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+/*
+RoundTrip implements http.RoundTripper.
+*/
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+/*
+ClientMiddleware is a http.RoundTripper wrapper,
+used to extend outbound requests processing (caching, tracing, etc).
+*/
+type ClientMiddleware func(http.RoundTripper) http.RoundTripper
+
+/*
+Client builds a new *http.Client with middlewares applied
+on top of http.DefaultTransport.
+The first middleware is the outermost one.
+
+Usage:
+
+	client := httpx.Client(
+		httpx.Cache(httpx.NewMemoryCacheStore()),
+	)
+	httpx.Request("GET", "https://example.com").Client(client).Do()
+*/
+func Client(middleware ...ClientMiddleware) *http.Client {
+	return &http.Client{
+		Transport: Chain(http.DefaultTransport, middleware...),
+	}
+}
+
+/*
+Chain applies middlewares to a given transport.
+The first middleware is the outermost one.
+Nil transport means http.DefaultTransport.
+*/
+func Chain(transport http.RoundTripper, middleware ...ClientMiddleware) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		transport = middleware[i](transport)
+	}
+
+	return transport
+}
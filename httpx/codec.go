@@ -0,0 +1,532 @@
+package httpx
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCodecUnsupportedType = errors.New("unsupported type")
+	ErrCodecUnsupportedKey  = errors.New("unsupported map key type, only string keys are allowed")
+	ErrCodecArrayLength     = errors.New("too many values for array")
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+/*
+FieldError describes a failure of encoding/decoding
+of a specific struct field.
+Field holds a Go field path (like "Filter.Min"),
+Key holds a resulting value key (like "filter[min]").
+*/
+type FieldError struct {
+	Tag   string
+	Field string
+	Key   string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("failed to process %s field %s (%s): %s", e.Tag, e.Field, e.Key, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// codecTag is a parsed struct field tag.
+type codecTag struct {
+	name      string
+	omitempty bool
+	comma     bool
+	def       *string
+}
+
+/*
+codecParseTag parses a struct field tag value.
+Supported options are "omitempty", "comma" and "default=...".
+Default must be the last option, because it may contain commas.
+*/
+func codecParseTag(raw string) codecTag {
+	parts := strings.Split(raw, ",")
+	tag := codecTag{name: parts[0]}
+	for i := 1; i < len(parts); i++ {
+		switch {
+		case parts[i] == "omitempty":
+			tag.omitempty = true
+		case parts[i] == "comma":
+			tag.comma = true
+		case strings.HasPrefix(parts[i], "default="):
+			def := strings.TrimPrefix(strings.Join(parts[i:], ","), "default=")
+			tag.def = &def
+
+			return tag
+		}
+	}
+
+	return tag
+}
+
+// codecKey composes a value key, using bracket notation for nested values.
+func codecKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "[" + name + "]"
+}
+
+// codecIsNested checks whether a type is encoded with bracket notation.
+func codecIsNested(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	return typ.Kind() == reflect.Struct || typ.Kind() == reflect.Map
+}
+
+// codecHasPrefix checks whether values contain at least one key with a given prefix.
+func codecHasPrefix(values map[string][]string, prefix string) bool {
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Encoding
+
+/*
+codecEncodeStruct walks through tagged struct fields
+and writes their values into the values map.
+*/
+func codecEncodeStruct(values map[string][]string, tagname, prefix string, ob reflect.Value) error {
+	typ := ob.Type()
+	for i := 0; i < ob.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		raw, ok := field.Tag.Lookup(tagname)
+		// Embedded structs without a tag are flattened
+		if !ok {
+			if field.Anonymous && codecIsNested(field.Type) && reflect.Indirect(ob.Field(i)).Kind() == reflect.Struct {
+				if err := codecEncodeStruct(values, tagname, prefix, reflect.Indirect(ob.Field(i))); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+		tag := codecParseTag(raw)
+		if tag.name == "-" {
+			continue
+		}
+		if tag.name == "" {
+			tag.name = field.Name
+		}
+		// Encode field value
+		key := codecKey(prefix, tag.name)
+		if err := codecEncodeValue(values, tagname, key, tag, ob.Field(i)); err != nil {
+			return codecFieldError(err, tagname, field.Name, key)
+		}
+	}
+
+	return nil
+}
+
+/*
+codecEncodeValue writes a single value under the given key.
+*/
+func codecEncodeValue(values map[string][]string, tagname, key string, tag codecTag, v reflect.Value) error {
+	// Skip empty values, if requested
+	if tag.omitempty && v.IsZero() {
+		return nil
+	}
+	// Dereference pointers and interfaces. Nil values have no representation
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	// Nested values
+	if codecIsNested(v.Type()) {
+		if v.Kind() == reflect.Struct {
+			return codecEncodeStruct(values, tagname, key, v)
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return ErrCodecUnsupportedKey
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			mkey := codecKey(key, iter.Key().String())
+			if err := codecEncodeValue(values, tagname, mkey, codecTag{comma: tag.comma}, iter.Value()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+	// Lists
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) &&
+		v.Type().Elem().Kind() != reflect.Uint8 && !v.Type().Implements(textMarshalerType) {
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := codecFormat(v.Index(i))
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		if tag.comma {
+			values[key] = []string{strings.Join(items, ",")}
+		} else {
+			values[key] = items
+		}
+
+		return nil
+	}
+	// Scalars
+	item, err := codecFormat(v)
+	if err != nil {
+		return err
+	}
+	values[key] = []string{item}
+
+	return nil
+}
+
+/*
+codecFormat converts a scalar value into a string.
+*/
+func codecFormat(v reflect.Value) (string, error) {
+	// Dereference pointers
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	// Text marshalers (time.Time, net.IP, etc.)
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText() //nolint:forcetypeassert
+		return string(text), err
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		text, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText() //nolint:forcetypeassert
+		return string(text), err
+	}
+	// Special types
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+	// Kinds
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrCodecUnsupportedType, v.Type())
+}
+
+// Decoding
+
+/*
+codecDecodeStruct walks through tagged struct fields
+and sets their values from the values map.
+*/
+func codecDecodeStruct(values map[string][]string, tagname, prefix string, ob reflect.Value) error {
+	typ := ob.Type()
+	for i := 0; i < ob.NumField(); i++ {
+		field := typ.Field(i)
+		fv := ob.Field(i)
+		if !fv.CanSet() {
+			continue
+		}
+		raw, ok := field.Tag.Lookup(tagname)
+		// Embedded structs without a tag are flattened
+		if !ok {
+			if field.Anonymous && codecIsNested(field.Type) && field.Type.Kind() != reflect.Map {
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						fv.Set(reflect.New(fv.Type().Elem()))
+					}
+					fv = fv.Elem()
+				}
+				if err := codecDecodeStruct(values, tagname, prefix, fv); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+		tag := codecParseTag(raw)
+		if tag.name == "-" {
+			continue
+		}
+		if tag.name == "" {
+			tag.name = field.Name
+		}
+		// Decode field value
+		key := codecKey(prefix, tag.name)
+		if err := codecDecodeValue(values, tagname, key, tag, fv); err != nil {
+			return codecFieldError(err, tagname, field.Name, key)
+		}
+	}
+
+	return nil
+}
+
+/*
+codecDecodeValue sets a single value from the given key.
+*/
+func codecDecodeValue(values map[string][]string, tagname, key string, tag codecTag, v reflect.Value) error {
+	// Nested values
+	if codecIsNested(v.Type()) {
+		// Leave nil pointers/maps untouched if there is nothing to decode
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Map) && !codecHasPrefix(values, key+"[") {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct {
+			return codecDecodeStruct(values, tagname, key, v)
+		}
+
+		return codecDecodeMap(values, key, tag, v)
+	}
+	// Resolve values, fallback to default
+	vals, ok := values[key]
+	if !ok || len(vals) == 0 {
+		if tag.def == nil {
+			return nil
+		}
+		vals = []string{*tag.def}
+		if codecIsList(v.Type()) {
+			vals = strings.Split(*tag.def, ",")
+		}
+	}
+
+	return codecSetValues(v, vals, tag.comma)
+}
+
+/*
+codecDecodeMap fills a string-keyed map from "key[name]" values.
+*/
+func codecDecodeMap(values map[string][]string, key string, tag codecTag, v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return ErrCodecUnsupportedKey
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	prefix := key + "["
+	for k, vals := range values {
+		if !strings.HasPrefix(k, prefix) || !strings.HasSuffix(k, "]") {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(k, prefix), "]")
+		if strings.ContainsAny(name, "[]") {
+			continue
+		}
+		item := reflect.New(v.Type().Elem()).Elem()
+		if err := codecSetValues(item, vals, tag.comma); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), item)
+	}
+
+	return nil
+}
+
+// codecIsList checks whether a type is decoded from multiple values.
+func codecIsList(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	return (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem().Kind() != reflect.Uint8
+}
+
+/*
+codecSetValues sets a value from a list of raw values.
+Lists are filled with all values (split by comma, if requested),
+other types are using the first value.
+*/
+func codecSetValues(v reflect.Value, vals []string, comma bool) error {
+	// Allocate pointers
+	if v.Kind() == reflect.Ptr {
+		ptr := reflect.New(v.Type().Elem())
+		if err := codecSetValues(ptr.Elem(), vals, comma); err != nil {
+			return err
+		}
+		v.Set(ptr)
+
+		return nil
+	}
+	// Lists
+	if codecIsList(v.Type()) {
+		items := vals
+		if comma {
+			items = []string{}
+			for _, val := range vals {
+				items = append(items, strings.Split(val, ",")...)
+			}
+		}
+		// Arrays are filled from the start, rest items are zero
+		var list reflect.Value
+		if v.Kind() == reflect.Array {
+			if len(items) > v.Len() {
+				return fmt.Errorf("%w: %d > %d", ErrCodecArrayLength, len(items), v.Len())
+			}
+			list = reflect.New(v.Type()).Elem()
+		} else {
+			list = reflect.MakeSlice(v.Type(), len(items), len(items))
+		}
+		for i, item := range items {
+			if err := codecSet(list.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+
+		return nil
+	}
+	// Scalars
+	return codecSet(v, vals[0])
+}
+
+/*
+codecSet parses a raw string into a scalar value.
+Empty strings are leaving non-string values untouched.
+*/
+func codecSet(v reflect.Value, val string) error {
+	// Allocate pointers
+	if v.Kind() == reflect.Ptr {
+		ptr := reflect.New(v.Type().Elem())
+		if err := codecSet(ptr.Elem(), val); err != nil {
+			return err
+		}
+		v.Set(ptr)
+
+		return nil
+	}
+	// Text unmarshalers (time.Time, net.IP, etc.)
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		if val == "" {
+			return nil
+		}
+
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val)) //nolint:forcetypeassert
+	}
+	// Special types
+	if v.Type() == durationType {
+		if val == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+
+		return nil
+	}
+	// Strings and bytes are set as-is
+	if v.Kind() == reflect.String {
+		v.SetString(val)
+
+		return nil
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		v.SetBytes([]byte(val))
+
+		return nil
+	}
+	// Empty value for other kinds means zero value
+	if val == "" {
+		return nil
+	}
+	// Kinds
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %s", ErrCodecUnsupportedType, v.Type())
+	}
+
+	return nil
+}
+
+/*
+codecFieldError wraps an error with a field information.
+Nested field errors are extended with a parent field name.
+*/
+func codecFieldError(err error, tagname, field, key string) error {
+	var ferr *FieldError
+	if errors.As(err, &ferr) {
+		ferr.Field = field + "." + ferr.Field
+
+		return ferr
+	}
+
+	return &FieldError{Tag: tagname, Field: field, Key: key, Err: err}
+}
@@ -0,0 +1,364 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrEncodingUnsupported = errors.New("unsupported content encoding")
+	ErrDecompressLimit     = errors.New("decompressed body exceeds size limit")
+)
+
+// DecompressMaxSize is a default decompressed body size limit.
+var DecompressMaxSize int64 = 32 << 20
+
+/*
+Encoding is a content encoding codec (Content-Encoding / Accept-Encoding).
+Package provides "gzip" and "deflate" codecs,
+others (like "zstd" or "br") might be plugged in with RegisterEncoding.
+
+Usage:
+
+	type zstdEncoding struct{}
+
+	func (zstdEncoding) Name() string { return "zstd" }
+	func (zstdEncoding) Encoder(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) }
+	func (zstdEncoding) Decoder(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r)
+		return d.IOReadCloser(), err
+	}
+
+	httpx.RegisterEncoding(zstdEncoding{})
+*/
+type Encoding interface {
+	Name() string
+	Encoder(w io.Writer) (io.WriteCloser, error)
+	Decoder(r io.Reader) (io.ReadCloser, error)
+}
+
+// encodings is a registry of content encodings.
+var encodings = struct {
+	lock  sync.RWMutex
+	names []string
+	codec map[string]Encoding
+}{
+	names: []string{"gzip", "deflate"},
+	codec: map[string]Encoding{
+		"gzip":    gzipEncoding{},
+		"deflate": deflateEncoding{},
+	},
+}
+
+/*
+RegisterEncoding registers (or replaces) a content encoding codec.
+*/
+func RegisterEncoding(enc Encoding) {
+	encodings.lock.Lock()
+	defer encodings.lock.Unlock()
+
+	name := strings.ToLower(enc.Name())
+	if _, ok := encodings.codec[name]; !ok {
+		encodings.names = append(encodings.names, name)
+	}
+	encodings.codec[name] = enc
+}
+
+/*
+Encodings returns registered content encoding names,
+in registration order.
+*/
+func Encodings() []string {
+	encodings.lock.RLock()
+	defer encodings.lock.RUnlock()
+
+	return append([]string{}, encodings.names...)
+}
+
+// encodingLookup finds a registered encoding by name.
+func encodingLookup(name string) (Encoding, error) {
+	encodings.lock.RLock()
+	defer encodings.lock.RUnlock()
+
+	enc, ok := encodings.codec[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEncodingUnsupported, name)
+	}
+
+	return enc, nil
+}
+
+// gzipEncoding is a built-in gzip codec.
+type gzipEncoding struct{}
+
+func (gzipEncoding) Name() string { return "gzip" }
+
+func (gzipEncoding) Encoder(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipEncoding) Decoder(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateEncoding is a built-in deflate codec.
+// HTTP "deflate" is a zlib stream (RFC 9110).
+type deflateEncoding struct{}
+
+func (deflateEncoding) Name() string { return "deflate" }
+
+func (deflateEncoding) Encoder(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+func (deflateEncoding) Decoder(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// Compression
+
+/*
+Compress compresses data with a given encoding.
+*/
+func Compress(name string, data []byte) ([]byte, error) {
+	enc, err := encodingLookup(name)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	writer, err := enc.Encoder(buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+/*
+Decompress wraps a body with decoders for a Content-Encoding header value
+(encodings are applied in listed order, so decoded in reverse).
+Decoded body is limited with maxsize (no limit if maxsize <= 0),
+reading beyond the limit fails with ErrDecompressLimit.
+*/
+func Decompress(body io.ReadCloser, contentencoding string, maxsize int64) (io.ReadCloser, error) {
+	// Collect encodings
+	names := []string{}
+	for _, name := range strings.Split(contentencoding, ",") {
+		if name = strings.TrimSpace(name); name != "" && !strings.EqualFold(name, "identity") {
+			names = append(names, name)
+		}
+	}
+	// Wrap decoders in reverse order
+	var reader io.Reader = body
+	closers := []io.Closer{body}
+	for i := len(names) - 1; i >= 0; i-- {
+		enc, err := encodingLookup(names[i])
+		if err != nil {
+			return nil, err
+		}
+		decoder, err := enc.Decoder(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader = decoder
+		closers = append(closers, decoder)
+	}
+	// Apply limit
+	if maxsize > 0 {
+		reader = &limitReader{reader: reader, left: maxsize}
+	}
+
+	return &decompressReader{Reader: reader, closers: closers}, nil
+}
+
+// limitReader fails with ErrDecompressLimit on reading beyond the limit.
+type limitReader struct {
+	reader io.Reader
+	left   int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.left <= 0 {
+		// Check whether there is something left
+		n, err := l.reader.Read(make([]byte, 1))
+		if n > 0 {
+			return 0, ErrDecompressLimit
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.left {
+		p = p[:l.left]
+	}
+	n, err := l.reader.Read(p)
+	l.left -= int64(n)
+
+	return n, err
+}
+
+// decompressReader closes decoders and underlying body.
+type decompressReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decompressReader) Close() error {
+	var err error
+	for i := len(d.closers) - 1; i >= 0; i-- {
+		if cerr := d.closers[i].Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// Builder and response methods
+
+/*
+Compress compresses request body with a given encoding
+and sets a Content-Encoding header.
+Body is compressed and header is set on Build (builder itself is not changed).
+If encoding is not registered, it panics.
+
+Usage:
+
+	httpx.Request("POST", "https://example.com").BodyJson(data).Compress("gzip").Do()
+*/
+func (r *RequestBuilder) Compress(name string) *RequestBuilder {
+	enc, err := encodingLookup(name)
+	if err != nil {
+		panic(err)
+	}
+	r.compress = enc.Name()
+
+	return r
+}
+
+/*
+AcceptEncoding sets an Accept-Encoding header
+(all registered encodings, if not provided).
+Go disables automatic gzip decoding when this header is set manually,
+so the response body is decoded by wrapper methods (Text, Unmarshal, Lines)
+or explicitly with ResponseWrapper.Decompress.
+*/
+func (r *RequestBuilder) AcceptEncoding(names ...string) *RequestBuilder {
+	if len(names) == 0 {
+		names = Encodings()
+	}
+	r.header["Accept-Encoding"] = []string{strings.Join(names, ", ")}
+
+	return r
+}
+
+// compressBody returns compressed builder body.
+// Original body is kept to allow builder reuse (retries).
+func (r *RequestBuilder) compressBody() io.Reader {
+	data, err := io.ReadAll(r.body)
+	if err != nil {
+		panic(err)
+	}
+	r.body = bytes.NewReader(data)
+	compressed, err := Compress(r.compress, data)
+	if err != nil {
+		panic(err)
+	}
+
+	return bytes.NewReader(compressed)
+}
+
+/*
+Decompress decodes response body according to Content-Encoding header.
+Optional parameter limits decoded body size (DecompressMaxSize by default).
+Content-Encoding and Content-Length headers are removed after decoding.
+If encoding is not supported, chain execution will be stopped.
+Returns wrapper for chaining.
+*/
+func (r *ResponseWrapper) Decompress(maxsize ...int64) *ResponseWrapper {
+	// Check error status
+	if r.err != nil {
+		return r
+	}
+	limit := DecompressMaxSize
+	if len(maxsize) > 0 {
+		limit = maxsize[0]
+	}
+	if err := r.decompress(limit); err != nil {
+		r.err = err
+	}
+	// Return wrapper
+	return r
+}
+
+// decompress decodes response body in-place, if encoded.
+func (r *ResponseWrapper) decompress(limit int64) error {
+	if r.Response == nil || r.Body == nil || r.Header.Get("Content-Encoding") == "" {
+		return nil
+	}
+	// Responses without body
+	if r.StatusCode == http.StatusNoContent || r.StatusCode == http.StatusNotModified ||
+		(r.Request != nil && r.Request.Method == http.MethodHead) {
+		return nil
+	}
+	body, err := Decompress(r.Body, r.Header.Get("Content-Encoding"), limit)
+	if err != nil {
+		r.Body.Close()
+		return err
+	}
+	r.Body = body
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	r.Uncompressed = true
+
+	return nil
+}
+
+/*
+Decompression is a ClientMiddleware, which requests compressed responses
+(sets Accept-Encoding, if missing) and decodes them transparently,
+limiting decoded body size with maxsize (no limit if maxsize <= 0).
+
+Usage:
+
+	client := httpx.Client(httpx.Decompression(httpx.DecompressMaxSize))
+*/
+func Decompression(maxsize int64, names ...string) ClientMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// Request compressed response
+			if req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" {
+				accept := names
+				if len(accept) == 0 {
+					accept = Encodings()
+				}
+				req = req.Clone(req.Context())
+				req.Header.Set("Accept-Encoding", strings.Join(accept, ", "))
+			}
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			// Decode response
+			// Body is closed on failure
+			if err := Response(resp).decompress(maxsize); err != nil {
+				return nil, err
+			}
+
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package httpx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// compressTestBody tracks body closing.
+type compressTestBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *compressTestBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("hello world ", 100))
+	for _, encoding := range []string{"gzip", "deflate"} {
+		compressed, err := Compress(encoding, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(compressed) >= len(data) {
+			t.Fatalf("%s: expected data to be compressed", encoding)
+		}
+		body, err := Decompress(io.NopCloser(bytes.NewReader(compressed)), strings.ToUpper(encoding), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := io.ReadAll(body)
+		if err != nil || !bytes.Equal(decoded, data) {
+			t.Fatalf("%s: expected round trip, got %d bytes, %v", encoding, len(decoded), err)
+		}
+	}
+	// Multiple encodings are decoded in reverse order
+	deflated, _ := Compress("deflate", data)
+	gzipped, _ := Compress("gzip", deflated)
+	body, err := Decompress(io.NopCloser(bytes.NewReader(gzipped)), "deflate, identity, gzip", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, _ := io.ReadAll(body); !bytes.Equal(decoded, data) {
+		t.Fatal("expected multiple encodings to be decoded")
+	}
+	// Unknown encoding
+	if _, err := Decompress(io.NopCloser(bytes.NewReader(data)), "br", 0); !errors.Is(err, ErrEncodingUnsupported) {
+		t.Fatalf("expected ErrEncodingUnsupported, got %v", err)
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	bomb, _ := Compress("gzip", make([]byte, 1<<20))
+	body, err := Decompress(io.NopCloser(bytes.NewReader(bomb)), "gzip", 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(body); !errors.Is(err, ErrDecompressLimit) {
+		t.Fatalf("expected ErrDecompressLimit, got %v", err)
+	}
+	// Exact size fits
+	exact, _ := Compress("gzip", make([]byte, 1024))
+	body, _ = Decompress(io.NopCloser(bytes.NewReader(exact)), "gzip", 1024)
+	if data, err := io.ReadAll(body); err != nil || len(data) != 1024 {
+		t.Fatalf("expected exact size to fit, got %d, %v", len(data), err)
+	}
+}
+
+func TestDecompressFailureClosesBody(t *testing.T) {
+	body := &compressTestBody{Reader: strings.NewReader("not gzip")}
+	response := Response(&http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Encoding": {"gzip"}},
+		Body:       body,
+	}, nil)
+	if err := response.Decompress().Error(); err == nil {
+		t.Fatal("expected decompression error")
+	}
+	if !body.closed {
+		t.Fatal("expected body to be closed on failure")
+	}
+}
+
+// compressTestServer responds with gzipped body, if accepted.
+func compressTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Echo request body, decoding it
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") != "" {
+			decoded, err := Decompress(r.Body, r.Header.Get("Content-Encoding"), 0)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			body = decoded
+		}
+		data, _ := io.ReadAll(body)
+		if len(data) == 0 {
+			data = []byte("hello")
+		}
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write(data) //nolint:errcheck
+			return
+		}
+		compressed, _ := Compress("gzip", data)
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed) //nolint:errcheck
+	}))
+}
+
+func TestDecompressionMiddleware(t *testing.T) {
+	server := compressTestServer(t)
+	defer server.Close()
+
+	client := Client(Decompression(DecompressMaxSize, "gzip"))
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != "hello" || resp.Header.Get("Content-Encoding") != "" {
+		t.Fatalf("expected decoded body, got %q (%s)", data, resp.Header.Get("Content-Encoding"))
+	}
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	server := compressTestServer(t)
+	defer server.Close()
+
+	// Go doesn't decode responses, if Accept-Encoding is set manually
+	text := Request("GET", server.URL).AcceptEncoding("gzip").Do().Text()
+	if text != "hello" {
+		t.Fatalf("expected decoded text, got %q", text)
+	}
+}
+
+func TestCompressRequest(t *testing.T) {
+	server := compressTestServer(t)
+	defer server.Close()
+
+	builder := Request("POST", server.URL).Body(strings.NewReader("payload")).Compress("deflate")
+	if text := builder.Do().Text(); text != "payload" {
+		t.Fatalf("expected compressed request to be decoded by server, got %q", text)
+	}
+	if _, ok := builder.header["Content-Encoding"]; ok {
+		t.Fatal("expected builder header not to be changed")
+	}
+	if request := builder.Build(); request.Header.Get("Content-Encoding") != "deflate" {
+		t.Fatalf("expected Content-Encoding on built request, got %v", request.Header)
+	}
+}
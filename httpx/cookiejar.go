@@ -0,0 +1,419 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+PublicSuffixList provides the public suffix of a domain.
+It's compatible with net/http/cookiejar.PublicSuffixList,
+so golang.org/x/net/publicsuffix.List might be used for complete rules.
+*/
+type PublicSuffixList interface {
+	PublicSuffix(domain string) string
+	String() string
+}
+
+/*
+DefaultPublicSuffixList is a minimal built-in public suffix list.
+It covers top-level domains and a set of common second-level
+registry suffixes (like "co.uk" or "com.au").
+As it's far from complete, CookieJar with this list downgrades
+Domain attributes, covering a whole two-label domain, to host-only cookies (check CookieJar).
+For complete rules, use golang.org/x/net/publicsuffix.List.
+*/
+var DefaultPublicSuffixList PublicSuffixList = minimalSuffixList{}
+
+// minimalSuffixList is a DefaultPublicSuffixList implementation.
+type minimalSuffixList struct{}
+
+// minimalSuffixes are common multi-label public suffixes.
+var minimalSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "ac.uk": true, "gov.uk": true, "me.uk": true,
+	"com.au": true, "net.au": true, "org.au": true, "edu.au": true, "gov.au": true,
+	"co.jp": true, "ne.jp": true, "or.jp": true, "ac.jp": true,
+	"co.nz": true, "org.nz": true, "co.za": true, "co.in": true, "co.kr": true,
+	"com.br": true, "com.cn": true, "com.mx": true, "com.tr": true, "com.ua": true,
+	"github.io": true, "herokuapp.com": true, "appspot.com": true, "blogspot.com": true,
+	"cloudfront.net": true, "azurewebsites.net": true, "vercel.app": true, "netlify.app": true,
+}
+
+func (minimalSuffixList) PublicSuffix(domain string) string {
+	labels := strings.Split(domain, ".")
+	if len(labels) >= 2 {
+		if suffix := strings.Join(labels[len(labels)-2:], "."); minimalSuffixes[suffix] {
+			return suffix
+		}
+	}
+
+	return labels[len(labels)-1]
+}
+
+func (minimalSuffixList) String() string {
+	return "zen/httpx minimal public suffix list"
+}
+
+/*
+JarCookie is a cookie, stored in CookieJar.
+*/
+type JarCookie struct {
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Domain   string        `json:"domain"`
+	Path     string        `json:"path"`
+	HostOnly bool          `json:"host_only"`
+	Secure   bool          `json:"secure"`
+	HttpOnly bool          `json:"http_only"` //nolint:revive,stylecheck
+	SameSite http.SameSite `json:"same_site"`
+	// Expires is zero for session cookies
+	Expires time.Time `json:"expires"`
+	Created time.Time `json:"created"`
+}
+
+// expired checks cookie expiration.
+func (c *JarCookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !now.Before(c.Expires)
+}
+
+// key returns cookie identity key.
+func (c *JarCookie) key() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+/*
+CookieJar is a http.CookieJar, which follows RFC 6265 domain and path rules,
+rejects cookies for public suffixes, and allows cookies inspection,
+editing and persistence.
+Session cookies (without expiration) are persisted as well.
+Use NewCookieJar to create it.
+
+With the built-in minimal public suffix list (default),
+two-label domains can't be distinguished from unknown public suffixes (like "com.sg"),
+so a Domain attribute, covering a whole two-label domain (like "example.com"),
+is downgraded to a host-only cookie for the request host.
+F.e. "Domain=example.com" from "www.example.com" is stored for "www.example.com" only,
+while "Domain=www.example.com" is stored as-is.
+Provide a complete list (golang.org/x/net/publicsuffix.List) to NewCookieJar to lift this restriction.
+*/
+type CookieJar struct {
+	suffixes PublicSuffixList
+	// strict downgrades two-label Domain attributes (minimal suffix list)
+	strict bool
+
+	lock    sync.Mutex
+	cookies map[string]*JarCookie
+}
+
+/*
+SetCookies implements http.CookieJar.
+*/
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	now := time.Now()
+	host := jarHost(u)
+	for _, cookie := range cookies {
+		stored, ok := j.cookie(host, u.Path, cookie, now)
+		if !ok {
+			continue
+		}
+		// Expired cookie is a deletion
+		if stored.expired(now) {
+			delete(j.cookies, stored.key())
+			continue
+		}
+		// Keep creation time on update
+		if existing, ok := j.cookies[stored.key()]; ok {
+			stored.Created = existing.Created
+		}
+		j.cookies[stored.key()] = stored
+	}
+}
+
+// cookie converts a received cookie into a stored one, validating domain.
+func (j *CookieJar) cookie(host, path string, cookie *http.Cookie, now time.Time) (*JarCookie, bool) {
+	stored := &JarCookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+		SameSite: cookie.SameSite,
+		Created:  now,
+	}
+	// Domain
+	domain := strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+	switch {
+	case domain == "":
+		stored.Domain, stored.HostOnly = host, true
+	case net.ParseIP(host) != nil:
+		// IP hosts accept host-only cookies only
+		if domain != host {
+			return nil, false
+		}
+		stored.Domain, stored.HostOnly = host, true
+	case j.suffixes != nil && j.suffixes.PublicSuffix(domain) == domain:
+		// Public suffix is allowed only as a host-only cookie for the same host
+		if domain != host {
+			return nil, false
+		}
+		stored.Domain, stored.HostOnly = host, true
+	case j.strict && strings.Count(domain, ".") < 2 && (host == domain || strings.HasSuffix(host, "."+domain)):
+		// Possible public suffix is downgraded to a host-only cookie for the request host
+		stored.Domain, stored.HostOnly = host, true
+	case host == domain || strings.HasSuffix(host, "."+domain):
+		stored.Domain = domain
+	default:
+		return nil, false
+	}
+	// Path
+	if stored.Path == "" || stored.Path[0] != '/' {
+		stored.Path = jarDefaultPath(path)
+	}
+	// Expiration
+	switch {
+	case cookie.MaxAge < 0:
+		stored.Expires = time.Unix(1, 0)
+	case cookie.MaxAge > 0:
+		stored.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+	case !cookie.Expires.IsZero():
+		stored.Expires = cookie.Expires
+	}
+
+	return stored, true
+}
+
+/*
+Cookies implements http.CookieJar.
+Cookies are sorted by path length (longest first), then by creation time.
+*/
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	matched := j.Match(u)
+	cookies := make([]*http.Cookie, 0, len(matched))
+	for _, cookie := range matched {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	return cookies
+}
+
+/*
+Match returns stored cookies, which would be sent to a given url.
+*/
+func (j *CookieJar) Match(u *url.URL) []JarCookie {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	var (
+		now     = time.Now()
+		host    = jarHost(u)
+		https   = u.Scheme == "https" || u.Scheme == "wss"
+		path    = u.Path
+		matched = []JarCookie{}
+	)
+	if path == "" {
+		path = "/"
+	}
+	for key, cookie := range j.cookies {
+		// Drop expired
+		if cookie.expired(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		// Check domain, path and security
+		if cookie.HostOnly && cookie.Domain != host {
+			continue
+		}
+		if !cookie.HostOnly && host != cookie.Domain && !strings.HasSuffix(host, "."+cookie.Domain) {
+			continue
+		}
+		if !jarPathMatch(path, cookie.Path) || (cookie.Secure && !https) {
+			continue
+		}
+		matched = append(matched, *cookie)
+	}
+	sort.Slice(matched, func(a, b int) bool {
+		if len(matched[a].Path) != len(matched[b].Path) {
+			return len(matched[a].Path) > len(matched[b].Path)
+		}
+		return matched[a].Created.Before(matched[b].Created)
+	})
+
+	return matched
+}
+
+/*
+All returns all stored (not expired) cookies, sorted by domain, path and name.
+*/
+func (j *CookieJar) All() []JarCookie {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	now := time.Now()
+	cookies := make([]JarCookie, 0, len(j.cookies))
+	for _, cookie := range j.cookies {
+		if !cookie.expired(now) {
+			cookies = append(cookies, *cookie)
+		}
+	}
+	sort.Slice(cookies, func(a, b int) bool {
+		return cookies[a].key() < cookies[b].key()
+	})
+
+	return cookies
+}
+
+/*
+Set stores a cookie as-is, replacing existing one
+with the same domain, path and name.
+Domain is required, path defaults to "/".
+*/
+func (j *CookieJar) Set(cookie JarCookie) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	cookie.Domain = strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if cookie.Created.IsZero() {
+		cookie.Created = time.Now()
+	}
+	j.cookies[cookie.key()] = &cookie
+}
+
+/*
+Delete removes cookies with a given name, matching domain (and path, if provided).
+*/
+func (j *CookieJar) Delete(domain, name string, path ...string) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	for key, cookie := range j.cookies {
+		if cookie.Domain == domain && cookie.Name == name && (len(path) == 0 || cookie.Path == path[0]) {
+			delete(j.cookies, key)
+		}
+	}
+}
+
+/*
+Clear removes all cookies.
+*/
+func (j *CookieJar) Clear() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.cookies = map[string]*JarCookie{}
+}
+
+/*
+Save writes all cookies into a json file.
+File is written atomically (temporary file and rename).
+*/
+func (j *CookieJar) Save(path string) error {
+	data, err := json.MarshalIndent(j.All(), "", "  ")
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+/*
+Load reads cookies from a json file, written with Save.
+Loaded cookies are merged with existing ones, expired are skipped.
+Missing file is not an error.
+*/
+func (j *CookieJar) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cookies := []JarCookie{}
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, cookie := range cookies {
+		if !cookie.expired(now) {
+			j.Set(cookie)
+		}
+	}
+
+	return nil
+}
+
+// jarHost returns a canonical host without port.
+func jarHost(u *url.URL) string {
+	return strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+}
+
+// jarDefaultPath returns RFC 6265 default cookie path.
+func jarDefaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+
+	return path[:i]
+}
+
+// jarPathMatch checks RFC 6265 path matching.
+func jarPathMatch(path, cookiepath string) bool {
+	if path == cookiepath {
+		return true
+	}
+	if strings.HasPrefix(path, cookiepath) {
+		return strings.HasSuffix(cookiepath, "/") || path[len(cookiepath)] == '/'
+	}
+
+	return false
+}
+
+/*
+NewCookieJar is a CookieJar constructor.
+If public suffix list is not provided, DefaultPublicSuffixList is used.
+With the built-in minimal list, two-label Domain attributes are downgraded
+to host-only cookies, check CookieJar for details.
+*/
+func NewCookieJar(suffixes ...PublicSuffixList) *CookieJar {
+	if len(suffixes) == 0 {
+		suffixes = append(suffixes, DefaultPublicSuffixList)
+	}
+	_, strict := suffixes[0].(minimalSuffixList)
+
+	return &CookieJar{
+		suffixes: suffixes[0],
+		strict:   strict,
+		cookies:  map[string]*JarCookie{},
+	}
+}
@@ -0,0 +1,54 @@
+package httpx
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// lastLabelSuffixList treats top-level domains only as public suffixes.
+type lastLabelSuffixList struct{}
+
+func (lastLabelSuffixList) PublicSuffix(domain string) string {
+	return domain[strings.LastIndex(domain, ".")+1:]
+}
+
+func (lastLabelSuffixList) String() string {
+	return "last label"
+}
+
+func TestCookieJarDomain(t *testing.T) {
+	tests := []struct {
+		url      string
+		domain   string
+		suffixes []PublicSuffixList
+		accepted bool
+		hostonly bool
+	}{
+		{"https://www.example.com", "", nil, true, true},
+		{"https://www.example.com", "www.example.com", nil, true, false},
+		{"https://a.www.example.com", "www.example.com", nil, true, false},
+		{"https://www.example.co.uk", "example.co.uk", nil, true, false},
+		{"https://www.example.co.uk", "co.uk", nil, false, false},
+		{"https://www.example.com", "com", nil, false, false},
+		{"https://www.example.com.sg", "com.sg", nil, true, true},
+		{"https://www.example.com", "example.com", nil, true, true},
+		{"https://www.example.com", "other.com", nil, false, false},
+		{"https://example.com", "example.com", nil, true, true},
+		{"https://www.example.com", "example.com", []PublicSuffixList{lastLabelSuffixList{}}, true, false},
+		{"https://www.example.com", "other.com", []PublicSuffixList{lastLabelSuffixList{}}, false, false},
+	}
+	for _, test := range tests {
+		jar := NewCookieJar(test.suffixes...)
+		u, _ := url.Parse(test.url)
+		jar.SetCookies(u, []*http.Cookie{{Name: "name", Value: "value", Domain: test.domain}})
+		cookies := jar.All()
+		if len(cookies) != 0 != test.accepted {
+			t.Fatalf("%s with domain %q: expected accepted %t, got %v", test.url, test.domain, test.accepted, cookies)
+		}
+		if test.accepted && cookies[0].HostOnly != test.hostonly {
+			t.Fatalf("%s with domain %q: expected host-only %t", test.url, test.domain, test.hostonly)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// redacted replaces secret values.
+const redacted = "REDACTED"
+
+// redactHeaders lists headers, which values are considered as secrets.
+var redactHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+	"X-Auth-Token":        true,
+}
+
+// redactParams lists query parameters, which values are considered as secrets.
+var redactParams = []string{"token", "key", "secret", "password", "signature"}
+
+// redactHeader checks whether header value must be redacted.
+func redactHeader(name string) bool {
+	return redactHeaders[name] || strings.Contains(strings.ToLower(name), "token")
+}
+
+// redactURL returns url string with secret query parameters redacted.
+func redactURL(href *url.URL) string {
+	clone := *href
+	if _, ok := clone.User.Password(); ok {
+		clone.User = url.UserPassword(clone.User.Username(), redacted)
+	}
+	query := clone.Query()
+	for k := range query {
+		for _, param := range redactParams {
+			if strings.Contains(strings.ToLower(k), param) {
+				query.Set(k, redacted)
+			}
+		}
+	}
+	clone.RawQuery = query.Encode()
+
+	return clone.String()
+}
+
+/*
+Curl returns an equivalent curl command for the request.
+Secrets (authorization headers, cookies, tokens in headers and query) are redacted.
+Body is buffered to keep the builder usable.
+Body is printed uncompressed, so Content-Encoding of Compress is omitted.
+
+Usage:
+
+	cmd := httpx.Request("POST", "https://example.com").
+		BearerToken("secret").
+		BodyJson(data).
+		Curl() // curl -X 'POST' -H 'Authorization: REDACTED' ...
+*/
+func (r *RequestBuilder) Curl() string {
+	parts := []string{"curl", "-X", curlQuote(r.method)}
+	// Headers, sorted for stable output
+	names := make([]string, 0, len(r.header))
+	for name := range r.header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if r.compress != "" && http.CanonicalHeaderKey(name) == "Content-Encoding" {
+			continue
+		}
+		for _, value := range r.header[name] {
+			if redactHeader(name) {
+				value = redacted
+			}
+			parts = append(parts, "-H", curlQuote(name+": "+value))
+		}
+	}
+	// Body
+	if r.body != nil {
+		data, err := io.ReadAll(r.body)
+		if err == nil && len(data) > 0 {
+			parts = append(parts, "--data-binary", curlQuote(string(data)))
+		}
+		r.body = bytes.NewReader(data)
+	}
+	// Url
+	parts = append(parts, curlQuote(redactURL(r.href)))
+
+	return strings.Join(parts, " ")
+}
+
+// curlQuote quotes a value for shell.
+func curlQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,63 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+/*
+DebugSink receives debug output of ResponseWrapper.Debug.
+*/
+type DebugSink interface {
+	Debug(response *http.Response, dump []byte)
+}
+
+/*
+DefaultDebugSink is used by Debug, if no sink is provided.
+Writes to stderr by default.
+*/
+var DefaultDebugSink DebugSink = WriterSink(os.Stderr) //nolint:gochecknoglobals
+
+/*
+DebugSinkFunc is an adapter to use ordinary functions as DebugSink.
+*/
+type DebugSinkFunc func(response *http.Response, dump []byte)
+
+// Debug implements DebugSink.
+func (f DebugSinkFunc) Debug(response *http.Response, dump []byte) {
+	f(response, dump)
+}
+
+/*
+WriterSink creates a DebugSink, which writes request url
+and raw response dump into a given writer.
+*/
+func WriterSink(w io.Writer) DebugSink {
+	return DebugSinkFunc(func(response *http.Response, dump []byte) {
+		if response.Request != nil {
+			fmt.Fprintln(w, redactURL(response.Request.URL))
+		}
+		fmt.Fprintln(w, string(dump))
+	})
+}
+
+/*
+LoggerSink creates a DebugSink, which calls a structured logging function
+with key-value pairs (method, url, status, dump).
+Signature is compatible with slog.Logger methods.
+
+Usage:
+
+	res.Debug(httpx.LoggerSink(logger.Debug))
+*/
+func LoggerSink(log func(msg string, args ...any)) DebugSink {
+	return DebugSinkFunc(func(response *http.Response, dump []byte) {
+		args := []any{"status", response.StatusCode, "dump", string(dump)}
+		if response.Request != nil {
+			args = append(args, "method", response.Request.Method, "url", redactURL(response.Request.URL))
+		}
+		log("httpx response", args...)
+	})
+}
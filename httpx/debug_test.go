@@ -0,0 +1,34 @@
+package httpx
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDebugSinks(t *testing.T) {
+	href, _ := url.Parse("https://example.com/items?token=abc")
+	response := &http.Response{StatusCode: 200, Request: &http.Request{Method: "GET", URL: href}}
+	// Writer
+	buffer := &bytes.Buffer{}
+	WriterSink(buffer).Debug(response, []byte("HTTP/1.1 200 OK"))
+	if out := buffer.String(); !strings.Contains(out, "token=REDACTED") || !strings.Contains(out, "HTTP/1.1 200 OK") {
+		t.Fatalf("expected redacted url and dump, got %q", out)
+	}
+	// Logger
+	var (
+		message string
+		args    []any
+	)
+	LoggerSink(func(msg string, a ...any) { message, args = msg, a }).Debug(response, []byte("dump"))
+	values := map[any]any{}
+	for i := 0; i+1 < len(args); i += 2 {
+		values[args[i]] = args[i+1]
+	}
+	if message == "" || values["status"] != 200 || values["method"] != "GET" || values["dump"] != "dump" ||
+		values["url"] != "https://example.com/items?token=REDACTED" {
+		t.Fatalf("expected structured args, got %v", args)
+	}
+}
@@ -26,6 +26,29 @@ Usage:
 	path.GetBefore("baz") // string{"bar"}
 	// GetBeforeWithIndex returns path token and it's index located before provided token.
 	path.GetBeforeWithIndex("baz") // string{"bar"}, 1
+	// Match checks path against a pattern and extracts typed parameters.
+	params, ok := httpx.Path("/users/42/posts/a/b").Match("/users/{id}/posts/{slug...}")
+	params.Int("id") // 42, nil
+	params.Get("slug") // "a/b"
+
+# Router
+
+For small services, package provides a trie-based Router.
+It supports method routing, path parameters, wildcards,
+route groups, middlewares and 405 handling.
+
+Usage:
+
+	router := httpx.NewRouter()
+	router.Use(Logger)
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := httpx.PathParams(r).Int("id")
+	})
+	router.Group("/admin", func(g *httpx.Router) {
+		g.Use(Auth)
+		g.Delete("/users/{id}", DeleteUser)
+	})
+	http.ListenAndServe(":8000", router)
 
 # Query
 
@@ -40,11 +63,67 @@ Usage:
 	// With a Query wrapper we can use Unmarshal method to unmarshal query values into a struct.
 	type QueryParams struct {
 		Foo string `query:"foo"`
-		Bar string `query:"bar"`
+		Bar string `query:"bar,omitempty"` // Skip empty value on encoding
+		Limit int `query:"limit,default=10"` // Default value on decoding
+		Tags []string `query:"tags,comma"` // tags=a,b instead of tags=a&tags=b
+		Filter struct {
+			Since time.Time `query:"since"`
+		} `query:"filter"` // Nested values, like filter[since]=...
 	}
 	var params QueryParams
 	err := query.Unmarshal(&params)
 
+	// Marshal does the opposite, using the same struct tags.
+	query := httpx.Query{}
+	err := query.Marshal(params)
+
+# Binding / Validation
+
+For server handlers, you can use Bind to fill a struct from request data.
+Each data source has its own struct tag (path, query, header, cookie, form, json).
+After binding, struct is validated with declarative "validate" tag rules.
+
+Usage:
+
+	type Input struct {
+		ID    int    `path:"id"`
+		Page  int    `query:"page,default=1" validate:"min=1"`
+		Token string `header:"X-Token" validate:"required"`
+		Name  string `json:"name" validate:"required,max=64"`
+		Role  string `json:"role" validate:"oneof=admin user"`
+	}
+
+	var input Input
+	err := httpx.Bind(r, &input)
+
+	// Validation errors are collected into a structured list,
+	// which can be written back as a 422 response.
+	var verrs httpx.ValidationErrors
+	if errors.As(err, &verrs) {
+		verrs.Write(w)
+	}
+
+# Server responses
+
+For server handlers, package provides response writers.
+
+Usage:
+
+	// Write json response.
+	httpx.WriteJSON(w, http.StatusOK, data)
+	// Write response in format, requested with "Accept" header (json, xml or text).
+	httpx.Write(w, r, http.StatusOK, data)
+	// Write RFC 7807 problem response.
+	httpx.WriteProblem(w, httpx.Problem{Status: http.StatusConflict, Detail: "already exists"})
+	// Write an error as a problem response, mapping it to status with httpx.ErrorStatus.
+	httpx.WriteError(w, httpx.ErrorWithStatus(http.StatusNotFound, err))
+
+	// Stream Server-Sent Events with heartbeats.
+	sse, err := httpx.NewSSEWriter(w, r, 15*time.Second)
+	defer sse.Close()
+	sse.LastEventID // Client "Last-Event-ID" header, to resume the stream
+	sse.Send(httpx.Event{ID: "1", Event: "update", Data: "..."})
+
 # Request / Response
 
 This package comes with a chainable request builder and response wrapper.
@@ -80,5 +159,175 @@ Usage:
 
 	err := res.Error() // Get processing error. If someting went wrong on any chain stage, it will be here.
 	txt := res.Text() // Get response body as a string.
+
+# Pagination
+
+Paginate walks through paged APIs with a given strategy.
+Built-in strategies are LinkPagination (RFC 5988 Link header),
+CursorPagination (cursor field in json body) and OffsetPagination (offset/limit query).
+
+Usage:
+
+	items, err := httpx.Paginate[Item](
+		httpx.Request("GET", "https://example.com/items"),
+		&httpx.CursorPagination{Param: "cursor", Field: "meta.next_cursor"},
+	).Limit(10).Context(ctx).All()
+
+	// Pages and Items are returning *httpx.Stream iterators.
+	pages := httpx.Paginate[Item](builder, &httpx.LinkPagination{}).Pages()
+
+# Client / Caching
+
+Client builds an *http.Client with a chain of transport middlewares.
+One of them is Cache, an opt-in RFC-compliant caching transport
+with pluggable storage (in-memory and on-disk stores, built on cache package, are provided).
+
+Usage:
+
+	store, err := httpx.NewDiskCacheStore("/tmp/httpcache") // or httpx.NewMemoryCacheStore()
+	client := httpx.Client(httpx.Cache(store))
+	res := httpx.Request("GET", "https://example.com").Client(client).Do()
+	res.Header.Get("X-Cache") // HIT, MISS, STALE or REVALIDATED
+
+# Authentication
+
+Request builder provides BasicAuth and BearerToken methods.
+For more advanced cases, there are HMACSigner (request signing)
+and ClientCredentials (OAuth2 client-credentials token source with caching
+and refreshing ahead of expiry), both usable as client middlewares.
+
+Usage:
+
+	httpx.Request("GET", "https://example.com").BasicAuth("user", "pass")
+	httpx.Request("GET", "https://example.com").BearerToken(token)
+
+	signer := &httpx.HMACSigner{KeyID: "app", Key: []byte("secret")}
+	source := &httpx.ClientCredentials{TokenURL: "...", ClientID: "...", ClientSecret: "..."}
+	client := httpx.Client(source.Transport, signer.Transport)
+
+# Circuit breaker / Bulkhead
+
+Breaker and Bulkhead are protecting outbound calls.
+Both might be used as client middlewares, or as generic function wrappers.
+Rejected calls are failing immediately with errors,
+which are mapped to 503 by ErrorStatus/WriteError.
+
+Usage:
+
+	breaker := httpx.NewBreaker(0.5, 30*time.Second) // Open on 50% failures, for 30 seconds
+	bulkhead := httpx.NewBulkhead(10, 100*time.Millisecond) // 10 concurrent requests per host
+	client := httpx.Client(breaker.Transport, bulkhead.Transport)
+
+	fetch := httpx.WithBreaker(breaker, func(ctx context.Context) (string, error) {
+		...
+	})
+	val, err := fetch(ctx)
+	errors.Is(err, httpx.ErrBreakerOpen) // true, if breaker is open
+
+# Hedging / Deadline budget
+
+Hedge sends extra requests for idempotent reads, if the first one is slow,
+and takes the first successful response.
+Budget limits request execution time and passes the remaining time downstream
+with BudgetHeader, DeadlineBudget middleware applies it on the server side.
+
+Usage:
+
+	httpx.Request("GET", "https://example.com/items").
+		Context(r.Context()).
+		Hedge(50*time.Millisecond, 3). // Up to 3 requests, 50ms apart
+		Budget(500*time.Millisecond).
+		Do()
+
+	router.Use(httpx.DeadlineBudget)
+
+# Streaming
+
+Response body might be consumed as a stream instead of buffering.
+Streams are closed on context cancellation (see RequestBuilder.Context).
+
+Usage:
+
+	// Stream body line by line.
+	lines := httpx.Request("GET", "https://example.com/log").Do().Lines()
+	// Stream newline-delimited json.
+	items := httpx.NDJSON[Item](httpx.Request("GET", "https://example.com/items").Do())
+	// Stream Server-Sent Events with automatic reconnection.
+	events := httpx.Request("GET", "https://example.com/events").Context(ctx).Do().Events()
+
+	// All of them are *httpx.Stream[T] iterators.
+	defer events.Close()
+	for events.Next() {
+		event := events.Value()
+	}
+	err := events.Err()
+
+# Compression
+
+Compression is opt-in. Request body might be compressed with Compress.
+AcceptEncoding requests compressed responses, which are decoded by Text, Unmarshal and Lines
+(Go doesn't decode them automatically when Accept-Encoding is set manually).
+Decoded body size is limited (DecompressMaxSize) to protect against decompression bombs.
+Built-in encodings are gzip and deflate, others might be plugged in with RegisterEncoding.
+
+Usage:
+
+	httpx.Request("POST", "https://example.com").
+		BodyJson(data).Compress("gzip").
+		AcceptEncoding().
+		Do().Unmarshal(&result)
+
+	// or, on client level
+	client := httpx.Client(httpx.Decompression(10 << 20))
+
+# Sessions
+
+Session wraps a client with a cookie jar, which follows RFC 6265 and public suffix rules,
+and might be persisted into a json file. Optionally, it extracts CSRF tokens
+(from a cookie, a header or a html meta tag) and re-injects them into unsafe requests.
+
+Usage:
+
+	session := httpx.NewSession()
+	session.CSRF = &httpx.CSRF{Cookie: "csrftoken", Header: "X-CSRFToken"}
+	session.Load("session.json")
+	defer session.Save("session.json")
+
+	session.Request("POST", "https://example.com/login").BodyForm(credentials).Do()
+	session.Cookies("https://example.com") // inspect
+	session.Jar.Delete("example.com", "tracking") // edit
+
+# Metrics / Tracing
+
+Trace middleware reports request lifecycle events
+(start, dns, connect, tls, first byte, done, retry) to hooks.
+Metrics is a built-in in-memory hook, which collects per-host
+latency histograms and error rates, and exports them in Prometheus text format.
+
+Usage:
+
+	metrics := httpx.NewMetrics()
+	client := httpx.Client(httpx.Trace(metrics))
+	http.Handle("/metrics", metrics)
+
+	host := metrics.Hosts()["example.com"]
+	log.Println(host.Latency.Quantile(0.99), host.ErrorRate())
+
+# Debugging
+
+Request builder might be exported as a curl command with Curl (secrets are redacted).
+Response Debug dumps a response into debug sinks (stderr by default,
+or any io.Writer / slog-compatible logger).
+HARRecorder captures client traffic into HAR 1.2 document.
+
+Usage:
+
+	fmt.Println(httpx.Request("GET", "https://example.com").BearerToken("secret").Curl())
+	httpx.Request("GET", "https://example.com").Do().Debug(httpx.LoggerSink(slog.Debug))
+
+	recorder := &httpx.HARRecorder{Redact: true}
+	client := httpx.Client(recorder.Transport)
+	...
+	recorder.Save("session.har")
 */
 package httpx
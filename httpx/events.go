@@ -0,0 +1,270 @@
+package httpx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventsRetry is a default reconnection delay for Events.
+var EventsRetry = 3 * time.Second
+
+// EventsMaxRetry limits reconnection delay, growing after network errors.
+var EventsMaxRetry = 1 * time.Minute
+
+// EventsAttempts limits consecutive failed reconnection attempts (network errors).
+var EventsAttempts = 10
+
+var ErrEventsStatus = errors.New("event stream reconnection failed with unexpected status")
+
+/*
+Events streams Server-Sent Events from response body.
+When connection is lost, stream reconnects automatically
+with the same request and "Last-Event-ID" header,
+waiting for a delay, provided by server "retry" field (EventsRetry by default).
+After network errors the delay is doubled (up to EventsMaxRetry),
+and reconnection gives up after EventsAttempts consecutive failures
+with the last error.
+Reconnection stops on context cancellation, stream close,
+204 No Content or non-2xx response.
+Request body is re-sent only if request provides GetBody.
+Encoded bodies (see RequestBuilder.AcceptEncoding) are decoded, including reconnected ones.
+Optional parameter limits line size (StreamMaxLineSize by default).
+
+Usage:
+
+	stream := httpx.Request("GET", "https://example.com/events").
+		Context(ctx).
+		Do().Success().
+		Events()
+	defer stream.Close()
+	for stream.Next() {
+		event := stream.Value()
+		log.Println(event.ID, event.Event, event.Data)
+	}
+*/
+func (r *ResponseWrapper) Events(maxsize ...int) *Stream[Event] {
+	// Check error status
+	if r.err != nil {
+		return streamError[Event](r.err)
+	}
+	// Decode body, size is limited per line
+	if err := r.decompress(0); err != nil {
+		return streamError[Event](err)
+	}
+	// Build reader
+	reader := &eventsReader{
+		response: r,
+		maxsize:  maxsize,
+		retry:    EventsRetry,
+	}
+	reader.reset(r.Body)
+	ctx := r.streamContext()
+
+	return NewStream(ctx, reader.next, reader.close)
+}
+
+// eventsReader parses event stream and handles reconnection.
+type eventsReader struct {
+	response *ResponseWrapper
+	maxsize  []int
+	retry    time.Duration
+	lastid   string
+
+	lock    sync.Mutex
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	closed  bool
+}
+
+// reset switches reader to a new body.
+func (e *eventsReader) reset(body io.ReadCloser) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.body = body
+	e.scanner = streamScanner(body, e.maxsize)
+}
+
+// swap switches reader to a reconnected body and closes the previous one.
+// Returns false (and closes a new body) if reader was closed in between.
+func (e *eventsReader) swap(body io.ReadCloser) bool {
+	e.lock.Lock()
+	if e.closed {
+		e.lock.Unlock()
+		body.Close()
+		return false
+	}
+	previous := e.body
+	e.body = body
+	e.scanner = streamScanner(body, e.maxsize)
+	e.lock.Unlock()
+	previous.Close()
+
+	return true
+}
+
+// close closes current body and prevents reconnection.
+func (e *eventsReader) close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.closed = true
+
+	return e.body.Close()
+}
+
+// next returns the next event, reconnecting if needed.
+func (e *eventsReader) next() (Event, error) {
+	for {
+		event, err := e.parse()
+		if err == nil {
+			return event, nil
+		}
+		// Don't reconnect on line size overflow or closed stream
+		if errors.Is(err, bufio.ErrTooLong) || e.isClosed() {
+			return Event{}, err
+		}
+		// Reconnect
+		if err := e.reconnect(); err != nil {
+			return Event{}, err
+		}
+	}
+}
+
+// isClosed checks whether reader was closed.
+func (e *eventsReader) isClosed() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.closed
+}
+
+/*
+parse reads lines until the next complete event.
+Follows WHATWG event stream interpretation rules.
+*/
+func (e *eventsReader) parse() (Event, error) {
+	var (
+		event   = Event{}
+		data    = []string{}
+		hasdata = false
+	)
+
+	for e.scanner.Scan() {
+		line := e.scanner.Text()
+		// Dispatch event on empty line
+		if line == "" {
+			if !hasdata {
+				event = Event{}
+				continue
+			}
+			event.ID = e.lastid
+			event.Data = strings.Join(data, "\n")
+
+			return event, nil
+		}
+		// Skip comments
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		// Parse field
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+			hasdata = true
+		case "event":
+			event.Event = value
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				e.lastid = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+				e.retry = time.Duration(ms) * time.Millisecond
+				event.Retry = e.retry
+			}
+		}
+	}
+
+	return Event{}, errOr(e.scanner.Err(), io.EOF)
+}
+
+// reconnect waits for retry delay and re-sends original request.
+// Network errors are retried with growing delay,
+// until attempts limit, context cancellation or close.
+func (e *eventsReader) reconnect() error {
+	var (
+		ctx      = e.response.streamContext()
+		original = e.response.Request
+		client   = e.response.client
+		delay    = e.retry
+	)
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Wait for retry delay
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if e.isClosed() {
+			return io.EOF
+		}
+		// Compose request
+		request := original.Clone(ctx)
+		if original.GetBody != nil {
+			body, err := original.GetBody()
+			if err != nil {
+				return err
+			}
+			request.Body = body
+		}
+		if e.lastid != "" {
+			request.Header.Set("Last-Event-ID", e.lastid)
+		}
+		// Execute request
+		resp, err := client.Do(request)
+		if err != nil {
+			if attempt >= EventsAttempts {
+				return err
+			}
+			// Back off
+			if delay *= 2; delay > EventsMaxRetry {
+				delay = EventsMaxRetry
+			}
+			continue
+		}
+		// Validate response
+		if resp.StatusCode == http.StatusNoContent {
+			resp.Body.Close()
+			return io.EOF
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			resp.Body.Close()
+			return fmt.Errorf("%w: %d", ErrEventsStatus, resp.StatusCode)
+		}
+		if err := Response(resp).decompress(0); err != nil {
+			return err
+		}
+		// Switch body
+		if !e.swap(resp.Body) {
+			return io.EOF
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,115 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEventsReconnect(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			fmt.Fprint(w, "retry: 1\nid: 1\ndata: first\n\n")
+		case 2:
+			if r.Header.Get("Last-Event-ID") != "1" {
+				t.Errorf("expected Last-Event-ID 1, got %q", r.Header.Get("Last-Event-ID"))
+			}
+			fmt.Fprint(w, "id: 2\ndata: second\n\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	stream := Request("GET", server.URL).Do().Success().Events()
+	defer stream.Close()
+	events := []string{}
+	for stream.Next() {
+		events = append(events, stream.Value().Data)
+	}
+	if len(events) != 2 || events[0] != "first" || events[1] != "second" {
+		t.Fatalf("expected first and second events, got %v", events)
+	}
+}
+
+func TestEventsCompressed(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if n > 2 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		// Padding comment keeps gzip from using stored (plain) blocks
+		padding := ": " + strings.Repeat("padding", 100) + "\n"
+		body, _ := Compress("gzip", []byte(fmt.Sprintf("%sretry: 1\nid: %d\ndata: event %d\n\n", padding, n, n)))
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	// Request compression explicitly, so transport doesn't decode it
+	stream := Request("GET", server.URL).Header("Accept-Encoding", "gzip").Do().Success().Events()
+	defer stream.Close()
+	events := []string{}
+	for stream.Next() {
+		events = append(events, stream.Value().Data)
+	}
+	if stream.Err() != nil {
+		t.Fatal(stream.Err())
+	}
+	if len(events) != 2 || events[0] != "event 1" || events[1] != "event 2" {
+		t.Fatalf("expected decoded events, got %v", events)
+	}
+}
+
+// eventsTestTransport serves the first request and fails the next ones.
+type eventsTestTransport struct {
+	server   http.RoundTripper
+	attempts int32
+}
+
+func (t *eventsTestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&t.attempts, 1) == 1 {
+		return t.server.RoundTrip(req)
+	}
+
+	return nil, errors.New("connection refused")
+}
+
+func TestEventsReconnectAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "retry: 1\ndata: only\n\n")
+	}))
+	defer server.Close()
+	attempts, maxretry := EventsAttempts, EventsMaxRetry
+	EventsAttempts, EventsMaxRetry = 3, 4*time.Millisecond
+	defer func() {
+		EventsAttempts, EventsMaxRetry = attempts, maxretry
+	}()
+
+	transport := &eventsTestTransport{server: http.DefaultTransport}
+	stream := Request("GET", server.URL).Client(&http.Client{Transport: transport}).Do().Success().Events()
+	defer stream.Close()
+	count := 0
+	for stream.Next() {
+		count++
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 event, got %d", count)
+	}
+	if stream.Err() == nil {
+		t.Fatalf("expected reconnection error")
+	}
+	// The first request and 3 failed reconnection attempts
+	if transport.attempts != 4 {
+		t.Fatalf("expected 4 attempts, got %d", transport.attempts)
+	}
+}
@@ -0,0 +1,367 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+HAR is a HTTP Archive 1.2 document.
+Only fields, required by specification, are included.
+*/
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is a HAR root log object.
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator describes HAR creator application.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a single request/response pair.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+// HARRequest describes a request.
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARResponse describes a response.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARNameValue is a generic name/value pair.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData describes a request body.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent describes a response body.
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings describes request timings, in milliseconds.
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HARMaxBodySize is a default limit of captured body size, in bytes.
+var HARMaxBodySize = 1 << 20
+
+/*
+HARRecorder is a client middleware, which captures
+requests and responses into HAR 1.2 document,
+which can be opened with browser devtools.
+Request bodies are buffered (on a request copy), response bodies are captured
+while being read, so streams are not blocked.
+Entry is recorded when response body is read till the end, or closed.
+Captured bodies are truncated to MaxBodySize.
+
+Usage:
+
+	recorder := &httpx.HARRecorder{Redact: true}
+	client := httpx.Client(recorder.Transport)
+	...
+	recorder.Save("session.har")
+*/
+type HARRecorder struct {
+	// Redact secrets (authorization headers, cookies, tokens in query)
+	Redact bool
+	// MaxBodySize limits captured body size (HARMaxBodySize by default)
+	MaxBodySize int
+
+	lock    sync.Mutex
+	entries []HAREntry
+}
+
+/*
+Transport is a ClientMiddleware.
+*/
+func (h *HARRecorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		// Capture request on a copy, original request must not be modified
+		req = req.Clone(req.Context())
+		reqbody, err := readBody(req)
+		if err != nil {
+			return nil, err
+		}
+		started := time.Now()
+		// Execute
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		waited := time.Since(started)
+		// Capture response while it's read, record on the end
+		capture := &harCapture{ReadCloser: resp.Body, limit: h.maxBodySize()}
+		resp.Body = &bodyCloser{ReadCloser: capture, eof: true, onclose: func() {
+			received := time.Since(started)
+			h.record(HAREntry{
+				StartedDateTime: started,
+				Time:            harMillis(received),
+				Request:         h.request(req, reqbody),
+				Response:        h.response(resp, capture.body.Bytes(), capture.size),
+				Timings: HARTimings{
+					Wait:    harMillis(waited),
+					Receive: harMillis(received - waited),
+				},
+			})
+		}}
+
+		return resp, nil
+	})
+}
+
+// harCapture copies read data into a limited buffer.
+type harCapture struct {
+	io.ReadCloser
+
+	body  bytes.Buffer
+	size  int
+	limit int
+}
+
+func (c *harCapture) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.size += n
+	if room := c.limit - c.body.Len(); room > 0 {
+		if room > n {
+			room = n
+		}
+		c.body.Write(p[:room])
+	}
+
+	return n, err
+}
+
+// maxBodySize returns captured body limit.
+func (h *HARRecorder) maxBodySize() int {
+	if h.MaxBodySize <= 0 {
+		return HARMaxBodySize
+	}
+
+	return h.MaxBodySize
+}
+
+// truncate limits captured body.
+func (h *HARRecorder) truncate(body []byte) []byte {
+	if len(body) > h.maxBodySize() {
+		return body[:h.maxBodySize()]
+	}
+
+	return body
+}
+
+// record appends an entry.
+func (h *HARRecorder) record(entry HAREntry) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.entries = append(h.entries, entry)
+}
+
+// request converts a request to HAR format.
+func (h *HARRecorder) request(req *http.Request, body []byte) HARRequest {
+	href := req.URL.String()
+	if h.Redact {
+		href = redactURL(req.URL)
+	}
+	harreq := HARRequest{
+		Method:      req.Method,
+		URL:         href,
+		HTTPVersion: req.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     h.headers(req.Header),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	for _, cookie := range req.Cookies() {
+		harreq.Cookies = append(harreq.Cookies, HARNameValue{cookie.Name, h.secret(cookie.Value)})
+	}
+	for k, values := range req.URL.Query() {
+		for _, v := range values {
+			harreq.QueryString = append(harreq.QueryString, HARNameValue{k, v})
+		}
+	}
+	if h.Redact {
+		for i, param := range harreq.QueryString {
+			for _, secret := range redactParams {
+				if strings.Contains(strings.ToLower(param.Name), secret) {
+					harreq.QueryString[i].Value = redacted
+				}
+			}
+		}
+	}
+	if len(body) > 0 {
+		harreq.PostData = &HARPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(h.truncate(body)),
+		}
+	}
+
+	return harreq
+}
+
+// response converts a response to HAR format.
+func (h *HARRecorder) response(resp *http.Response, body []byte, size int) HARResponse {
+	harresp := HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     h.headers(resp.Header),
+		Content: HARContent{
+			Size:     size,
+			MimeType: resp.Header.Get("Content-Type"),
+		},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    size,
+	}
+	for _, cookie := range resp.Cookies() {
+		harresp.Cookies = append(harresp.Cookies, HARNameValue{cookie.Name, h.secret(cookie.Value)})
+	}
+	if utf8.Valid(body) {
+		harresp.Content.Text = string(body)
+	} else {
+		harresp.Content.Text = base64.StdEncoding.EncodeToString(body)
+		harresp.Content.Encoding = "base64"
+	}
+
+	return harresp
+}
+
+// headers converts headers to HAR format.
+func (h *HARRecorder) headers(header http.Header) []HARNameValue {
+	values := []HARNameValue{}
+	for k, vals := range header {
+		for _, v := range vals {
+			if redactHeader(k) {
+				v = h.secret(v)
+			}
+			values = append(values, HARNameValue{k, v})
+		}
+	}
+
+	return values
+}
+
+// secret redacts a value, if redaction is enabled.
+func (h *HARRecorder) secret(value string) string {
+	if h.Redact {
+		return redacted
+	}
+
+	return value
+}
+
+/*
+HAR returns a HAR document with captured entries.
+*/
+func (h *HARRecorder) HAR() HAR {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "zen/httpx", Version: "3"},
+		Entries: append([]HAREntry{}, h.entries...),
+	}}
+}
+
+/*
+WriteTo writes a HAR document as json.
+*/
+func (h *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(h.HAR(), "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+
+	return int64(n), err
+}
+
+/*
+Save writes a HAR document into a file.
+*/
+func (h *HARRecorder) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := h.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+/*
+Reset removes captured entries.
+*/
+func (h *HARRecorder) Reset() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.entries = nil
+}
+
+// harMillis converts duration to HAR milliseconds.
+func harMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,116 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHARRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(append([]byte("echo "), body...)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	recorder := &HARRecorder{Redact: true}
+	client := &http.Client{Transport: Chain(nil, recorder.Transport)}
+	req, _ := http.NewRequest("POST", server.URL+"/items?api_key=abc", io.NopCloser(strings.NewReader("hello")))
+	req.Header.Set("Authorization", "Bearer secret")
+	body := req.Body
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Original request is not modified
+	if req.Body != body || req.GetBody != nil {
+		t.Fatal("expected original request body to stay intact")
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != "echo hello" {
+		t.Fatalf("expected response body to be passed through, got %q", data)
+	}
+	// Entry
+	har := recorder.HAR()
+	if len(har.Log.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(har.Log.Entries))
+	}
+	entry := har.Log.Entries[0]
+	if entry.Request.PostData == nil || entry.Request.PostData.Text != "hello" {
+		t.Fatalf("expected request body, got %+v", entry.Request.PostData)
+	}
+	if entry.Response.Content.Text != "echo hello" || entry.Response.Content.Size != 10 {
+		t.Fatalf("expected response content, got %+v", entry.Response.Content)
+	}
+	if !strings.Contains(entry.Request.URL, "api_key=REDACTED") {
+		t.Fatalf("expected redacted url, got %s", entry.Request.URL)
+	}
+	for _, header := range entry.Request.Headers {
+		if header.Name == "Authorization" && header.Value != redacted {
+			t.Fatalf("expected redacted authorization, got %s", header.Value)
+		}
+	}
+	// Document
+	buffer := &bytes.Buffer{}
+	if _, err := recorder.WriteTo(buffer); err != nil {
+		t.Fatal(err)
+	}
+	document := HAR{}
+	if err := json.Unmarshal(buffer.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if document.Log.Version != "1.2" || len(document.Log.Entries) != 1 {
+		t.Fatalf("expected HAR 1.2 document with 1 entry, got %+v", document.Log)
+	}
+}
+
+func TestHARRecorderStream(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first\n")) //nolint:errcheck
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	recorder := &HARRecorder{MaxBodySize: 3}
+	client := &http.Client{Transport: Chain(nil, recorder.Transport)}
+	done := make(chan *http.Response)
+	go func() {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- resp
+	}()
+	var resp *http.Response
+	select {
+	case resp = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected streamed response not to be blocked")
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line != "first\n" {
+		t.Fatalf("expected first line, got %q, %v", line, err)
+	}
+	if len(recorder.HAR().Log.Entries) != 0 {
+		t.Fatal("expected entry to be recorded on close")
+	}
+	resp.Body.Close()
+	entries := recorder.HAR().Log.Entries
+	if len(entries) != 1 || entries[0].Response.Content.Text != "fir" {
+		t.Fatalf("expected truncated entry on close, got %+v", entries)
+	}
+}
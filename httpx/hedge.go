@@ -0,0 +1,111 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yznts/zen/v3/async"
+)
+
+var errHedgeLost = errors.New("hedged request lost the race")
+
+/*
+Hedge enables hedged requests to cut tail latency.
+If the request hasn't answered after a given delay, one more request is sent,
+up to max requests in total (including the first one).
+The first successful response wins, other requests are cancelled.
+Transport errors don't win, if there are pending requests.
+Use it only for idempotent requests. Body is buffered to be resent.
+
+Usage:
+
+	httpx.Request("GET", "https://example.com/items").
+		Hedge(50*time.Millisecond, 3).
+		Do()
+*/
+func (r *RequestBuilder) Hedge(after time.Duration, max int) *RequestBuilder {
+	r.hedgeAfter = after
+	r.hedgeMax = max
+
+	return r
+}
+
+// hedge executes hedged requests and returns the first successful response.
+func (r *RequestBuilder) hedge(request *http.Request) (*http.Response, error) {
+	// Buffer body to resend it
+	if _, err := readBody(request); err != nil {
+		return nil, err
+	}
+	var (
+		// Pending requests are not started after the winner is found
+		pending, stop = context.WithCancel(request.Context())
+		lock          sync.Mutex
+		won           bool
+		cancels       = make([]context.CancelFunc, r.hedgeMax)
+		futures       = make([]*async.Future[*http.Response], r.hedgeMax)
+	)
+	// Each request has own context to be cancelled separately
+	contexts := make([]context.Context, r.hedgeMax)
+	for i := range contexts {
+		contexts[i], cancels[i] = context.WithCancel(request.Context())
+	}
+	for i := range futures {
+		index, ctx, cancel := i, contexts[i], cancels[i]
+		delay := r.hedgeAfter * time.Duration(i)
+		futures[i] = async.New(func() (*http.Response, error) {
+			// Wait for the turn
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+				defer timer.Stop()
+				select {
+				case <-timer.C:
+				case <-pending.Done():
+					cancel()
+					return nil, errOr(request.Context().Err(), errHedgeLost)
+				}
+			}
+			// Execute
+			attempt := request.Clone(ctx)
+			if request.GetBody != nil {
+				body, err := request.GetBody()
+				if err != nil {
+					cancel()
+					return nil, err
+				}
+				attempt.Body = body
+			}
+			r.budgetHeader(attempt)
+			resp, err := r.client.Do(attempt)
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			// Claim the win, or discard response
+			lock.Lock()
+			defer lock.Unlock()
+			if won {
+				resp.Body.Close()
+				cancel()
+				return nil, errHedgeLost
+			}
+			won = true
+			stop()
+			for j, other := range cancels {
+				if j != index {
+					other()
+				}
+			}
+			// Release context on body close
+			resp.Body = &bodyCloser{ReadCloser: resp.Body, onclose: cancel}
+
+			return resp, nil
+		})
+	}
+	resp, err := async.Any(futures...).Await()
+	stop()
+
+	return resp, err
+}
@@ -0,0 +1,173 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+/*
+Hook receives client request lifecycle events.
+DNS, connect and TLS durations are measured from the phase start,
+first byte and done durations are measured from the request start.
+Connection phases are reported only for new connections.
+Embed NopHook to implement only needed methods.
+*/
+type Hook interface {
+	// RequestStart is called before request is sent
+	RequestStart(req *http.Request)
+	// DNSDone is called after host lookup
+	DNSDone(req *http.Request, duration time.Duration, err error)
+	// ConnectDone is called after a new connection dial
+	ConnectDone(req *http.Request, duration time.Duration, err error)
+	// TLSDone is called after TLS handshake
+	TLSDone(req *http.Request, duration time.Duration, err error)
+	// FirstByte is called on the first response byte
+	FirstByte(req *http.Request, duration time.Duration)
+	// Done is called on response body exhaustion/close, or on request error
+	Done(req *http.Request, resp *http.Response, duration time.Duration, err error)
+	// Retry is called before request retry (see RequestBuilder.Retry)
+	Retry(req *http.Request, attempt int)
+}
+
+/*
+NopHook is a Hook, which does nothing.
+Embed it into own hooks to implement only needed methods.
+*/
+type NopHook struct{}
+
+func (NopHook) RequestStart(*http.Request)                               {}
+func (NopHook) DNSDone(*http.Request, time.Duration, error)              {}
+func (NopHook) ConnectDone(*http.Request, time.Duration, error)          {}
+func (NopHook) TLSDone(*http.Request, time.Duration, error)              {}
+func (NopHook) FirstByte(*http.Request, time.Duration)                   {}
+func (NopHook) Done(*http.Request, *http.Response, time.Duration, error) {}
+func (NopHook) Retry(*http.Request, int)                                 {}
+
+// hookAttemptKey is a context key for request attempt number.
+type hookAttemptKey struct{}
+
+// withAttempt marks request context with attempt number.
+func withAttempt(req *http.Request, attempt int) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), hookAttemptKey{}, attempt))
+}
+
+/*
+Trace is a ClientMiddleware, which reports request lifecycle events to hooks.
+Connection events are collected with httptrace.
+
+Usage:
+
+	metrics := httpx.NewMetrics()
+	client := httpx.Client(httpx.Trace(metrics))
+	http.Handle("/metrics", metrics)
+*/
+func Trace(hooks ...Hook) ClientMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// Report retry
+			if attempt, ok := req.Context().Value(hookAttemptKey{}).(int); ok && attempt > 0 {
+				for _, hook := range hooks {
+					hook.Retry(req, attempt)
+				}
+			}
+			// Report start
+			start := time.Now()
+			for _, hook := range hooks {
+				hook.RequestStart(req)
+			}
+			// Execute with trace
+			tracer := &hookTracer{req: req, hooks: hooks, start: start, dials: map[string]time.Time{}}
+			resp, err := next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.trace())))
+			if err != nil {
+				tracer.done(nil, err)
+				return nil, err
+			}
+			// Report done on body exhaustion
+			resp.Body = &bodyCloser{ReadCloser: resp.Body, eof: true, onclose: func() {
+				tracer.done(resp, nil)
+			}}
+
+			return resp, nil
+		})
+	}
+}
+
+// hookTracer translates httptrace events to hooks.
+type hookTracer struct {
+	req   *http.Request
+	hooks []Hook
+	start time.Time
+
+	lock  sync.Mutex
+	dns   time.Time
+	dials map[string]time.Time
+	tls   time.Time
+}
+
+// trace builds httptrace hooks.
+func (t *hookTracer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.lock.Lock()
+			t.dns = time.Now()
+			t.lock.Unlock()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			duration := t.since(&t.dns)
+			for _, hook := range t.hooks {
+				hook.DNSDone(t.req, duration, info.Err)
+			}
+		},
+		// Dials might be concurrent (RFC 6555), so they are tracked by address
+		ConnectStart: func(network, addr string) {
+			t.lock.Lock()
+			t.dials[network+addr] = time.Now()
+			t.lock.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.lock.Lock()
+			duration := time.Since(t.dials[network+addr])
+			t.lock.Unlock()
+			for _, hook := range t.hooks {
+				hook.ConnectDone(t.req, duration, err)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.lock.Lock()
+			t.tls = time.Now()
+			t.lock.Unlock()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			duration := t.since(&t.tls)
+			for _, hook := range t.hooks {
+				hook.TLSDone(t.req, duration, err)
+			}
+		},
+		GotFirstResponseByte: func() {
+			duration := time.Since(t.start)
+			for _, hook := range t.hooks {
+				hook.FirstByte(t.req, duration)
+			}
+		},
+	}
+}
+
+// since returns duration since a phase start, under lock.
+func (t *hookTracer) since(start *time.Time) time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return time.Since(*start)
+}
+
+// done reports request completion.
+func (t *hookTracer) done(resp *http.Response, err error) {
+	duration := time.Since(t.start)
+	for _, hook := range t.hooks {
+		hook.Done(t.req, resp, duration, err)
+	}
+}
@@ -0,0 +1,329 @@
+package httpx
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yznts/zen/v3/internal/promtext"
+)
+
+// MetricsBuckets are default histogram buckets, in seconds.
+var MetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+/*
+Histogram is a cumulative histogram with fixed buckets.
+It's not safe for concurrent use by itself, Metrics guards it.
+*/
+type Histogram struct {
+	// Buckets are upper bounds, sorted
+	Buckets []float64
+	// Counts are cumulative counts for each bucket
+	Counts []uint64
+	Sum    float64
+	Count  uint64
+}
+
+/*
+Observe records a value.
+*/
+func (h *Histogram) Observe(value float64) {
+	for i, bound := range h.Buckets {
+		if value <= bound {
+			h.Counts[i]++
+		}
+	}
+	h.Sum += value
+	h.Count++
+}
+
+/*
+Mean returns an average value.
+*/
+func (h *Histogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+
+	return h.Sum / float64(h.Count)
+}
+
+/*
+Quantile estimates a quantile (0..1) with linear interpolation
+within a bucket, like Prometheus histogram_quantile does.
+Values beyond the last bucket are reported as the last bucket bound.
+*/
+func (h *Histogram) Quantile(q float64) float64 {
+	if h.Count == 0 || len(h.Buckets) == 0 {
+		return 0
+	}
+	rank := q * float64(h.Count)
+	lower, prev := 0.0, uint64(0)
+	for i, bound := range h.Buckets {
+		if float64(h.Counts[i]) >= rank {
+			inbucket := h.Counts[i] - prev
+			if inbucket == 0 {
+				return bound
+			}
+			return lower + (bound-lower)*(rank-float64(prev))/float64(inbucket)
+		}
+		lower, prev = bound, h.Counts[i]
+	}
+
+	return h.Buckets[len(h.Buckets)-1]
+}
+
+// clone returns a histogram copy.
+func (h *Histogram) clone() *Histogram {
+	return &Histogram{
+		Buckets: h.Buckets,
+		Counts:  append([]uint64{}, h.Counts...),
+		Sum:     h.Sum,
+		Count:   h.Count,
+	}
+}
+
+// newHistogram creates an empty histogram.
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{Buckets: buckets, Counts: make([]uint64, len(buckets))}
+}
+
+/*
+HostMetrics is a per-host metrics snapshot.
+Phase histograms are keyed by "dns", "connect", "tls" and "first_byte".
+*/
+type HostMetrics struct {
+	Requests uint64
+	Errors   uint64
+	Retries  uint64
+	Latency  *Histogram
+	Phases   map[string]*Histogram
+}
+
+/*
+ErrorRate returns errors to requests ratio.
+*/
+func (m HostMetrics) ErrorRate() float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+
+	return float64(m.Errors) / float64(m.Requests)
+}
+
+/*
+Metrics is an in-memory metrics collector Hook.
+It collects per-host request counts, errors (transport errors and 5xx responses),
+retries, latency and connection phases histograms.
+Metrics is a http.Handler, which exports collected metrics
+in Prometheus text format.
+
+Usage:
+
+	metrics := httpx.NewMetrics()
+	client := httpx.Client(httpx.Trace(metrics))
+	http.Handle("/metrics", metrics)
+	...
+	host := metrics.Hosts()["example.com"]
+	log.Println(host.Latency.Quantile(0.99), host.ErrorRate())
+*/
+type Metrics struct {
+	NopHook
+
+	// Namespace is a metric names prefix. Defaults to "httpx_client"
+	Namespace string
+	// Buckets are histogram buckets, in seconds. Defaults to MetricsBuckets
+	Buckets []float64
+
+	lock  sync.Mutex
+	hosts map[string]*HostMetrics
+}
+
+// host returns host metrics, creating if missing. Must be called under lock.
+func (m *Metrics) host(req *http.Request) *HostMetrics {
+	if m.hosts == nil {
+		m.hosts = map[string]*HostMetrics{}
+	}
+	host := req.URL.Host
+	if _, ok := m.hosts[host]; !ok {
+		buckets := m.Buckets
+		if len(buckets) == 0 {
+			buckets = MetricsBuckets
+		}
+		m.hosts[host] = &HostMetrics{
+			Latency: newHistogram(buckets),
+			Phases: map[string]*Histogram{
+				"dns":        newHistogram(buckets),
+				"connect":    newHistogram(buckets),
+				"tls":        newHistogram(buckets),
+				"first_byte": newHistogram(buckets),
+			},
+		}
+	}
+
+	return m.hosts[host]
+}
+
+// phase records a phase duration.
+func (m *Metrics) phase(req *http.Request, name string, duration time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.host(req).Phases[name].Observe(duration.Seconds())
+}
+
+// DNSDone records dns lookup duration.
+func (m *Metrics) DNSDone(req *http.Request, duration time.Duration, err error) {
+	m.phase(req, "dns", duration)
+}
+
+// ConnectDone records connect duration.
+func (m *Metrics) ConnectDone(req *http.Request, duration time.Duration, err error) {
+	m.phase(req, "connect", duration)
+}
+
+// TLSDone records TLS handshake duration.
+func (m *Metrics) TLSDone(req *http.Request, duration time.Duration, err error) {
+	m.phase(req, "tls", duration)
+}
+
+// FirstByte records time to first byte.
+func (m *Metrics) FirstByte(req *http.Request, duration time.Duration) {
+	m.phase(req, "first_byte", duration)
+}
+
+// Done records request result and latency.
+func (m *Metrics) Done(req *http.Request, resp *http.Response, duration time.Duration, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	host := m.host(req)
+	host.Requests++
+	if err != nil || (resp != nil && resp.StatusCode >= 500) {
+		host.Errors++
+	}
+	host.Latency.Observe(duration.Seconds())
+}
+
+// Retry records a retry.
+func (m *Metrics) Retry(req *http.Request, attempt int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.host(req).Retries++
+}
+
+/*
+Hosts returns a per-host metrics snapshot.
+*/
+func (m *Metrics) Hosts() map[string]HostMetrics {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	snapshot := map[string]HostMetrics{}
+	for name, host := range m.hosts {
+		phases := map[string]*Histogram{}
+		for phase, histogram := range host.Phases {
+			phases[phase] = histogram.clone()
+		}
+		snapshot[name] = HostMetrics{
+			Requests: host.Requests,
+			Errors:   host.Errors,
+			Retries:  host.Retries,
+			Latency:  host.Latency.clone(),
+			Phases:   phases,
+		}
+	}
+
+	return snapshot
+}
+
+/*
+Reset removes collected metrics.
+*/
+func (m *Metrics) Reset() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.hosts = nil
+}
+
+/*
+WriteTo writes metrics in Prometheus text exposition format.
+*/
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var (
+		hosts     = m.Hosts()
+		names     = make([]string, 0, len(hosts))
+		namespace = m.Namespace
+		out       = &strings.Builder{}
+	)
+	if namespace == "" {
+		namespace = "httpx_client"
+	}
+	for name := range hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// Counters
+	counters := []struct {
+		name, help string
+		value      func(HostMetrics) uint64
+	}{
+		{"requests_total", "Total number of requests.", func(h HostMetrics) uint64 { return h.Requests }},
+		{"errors_total", "Total number of failed requests (transport errors and 5xx responses).", func(h HostMetrics) uint64 { return h.Errors }},
+		{"retries_total", "Total number of retries.", func(h HostMetrics) uint64 { return h.Retries }},
+	}
+	for _, counter := range counters {
+		metric := namespace + "_" + counter.name
+		promtext.Header(out, metric, "counter", counter.help)
+		for _, name := range names {
+			promtext.Sample(out, metric, "host="+promtext.Label(name), strconv.FormatUint(counter.value(hosts[name]), 10))
+		}
+	}
+	// Latency
+	metric := namespace + "_request_duration_seconds"
+	promtext.Header(out, metric, "histogram", "Request duration, until response body is read.")
+	for _, name := range names {
+		metricsHistogram(out, metric, "host="+promtext.Label(name), hosts[name].Latency)
+	}
+	// Phases
+	metric = namespace + "_phase_duration_seconds"
+	promtext.Header(out, metric, "histogram", "Request phases duration (dns, connect, tls, first_byte).")
+	for _, name := range names {
+		for _, phase := range []string{"dns", "connect", "tls", "first_byte"} {
+			labels := "host=" + promtext.Label(name) + ",phase=" + promtext.Label(phase)
+			metricsHistogram(out, metric, labels, hosts[name].Phases[phase])
+		}
+	}
+	// Write
+	n, err := io.WriteString(w, out.String())
+
+	return int64(n), err
+}
+
+/*
+ServeHTTP exports metrics in Prometheus text format.
+*/
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	promtext.Serve(w, m)
+}
+
+// metricsHistogram writes a histogram in Prometheus text format.
+func metricsHistogram(w io.Writer, metric, labels string, h *Histogram) {
+	promtext.Histogram(w, metric, labels, h.Buckets, h.Counts, h.Sum, h.Count)
+}
+
+/*
+NewMetrics is a Metrics constructor.
+*/
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Namespace: "httpx_client",
+		Buckets:   MetricsBuckets,
+	}
+}
@@ -0,0 +1,423 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrPageItems = errors.New("failed to find page items in response body")
+
+/*
+PageStrategy defines how to request pages of a paged API.
+First prepares the first page request,
+Next prepares the next page request from a previous page
+and returns false if there are no more pages.
+
+Strategies might implement "ItemsPath() string" method
+to point to items array in json body (dot-separated path).
+Otherwise, body must be an array, or an object with
+"items", "data" or "results" array field.
+*/
+type PageStrategy interface {
+	First(b *RequestBuilder)
+	Next(b *RequestBuilder, page *PageResponse) bool
+}
+
+/*
+PageResponse holds raw page response details, passed to PageStrategy.
+*/
+type PageResponse struct {
+	Response *http.Response
+	Body     []byte
+	Items    int
+	// Offset is a number of items on previous pages
+	Offset int
+}
+
+/*
+Page is a single decoded page.
+*/
+type Page[T any] struct {
+	Number int
+	Items  []T
+	Header http.Header
+}
+
+/*
+Pagination iterates over a paged API with a given strategy.
+Request builder is reused and modified for each page,
+so it must not have a one-shot body.
+Use Paginate to create it.
+*/
+type Pagination[T any] struct {
+	builder  *RequestBuilder
+	strategy PageStrategy
+	limit    int
+	ctx      context.Context //nolint:containedctx
+}
+
+/*
+Limit limits a number of pages to fetch.
+*/
+func (p *Pagination[T]) Limit(pages int) *Pagination[T] {
+	p.limit = pages
+
+	return p
+}
+
+/*
+Context sets a context for page requests and iteration.
+*/
+func (p *Pagination[T]) Context(ctx context.Context) *Pagination[T] {
+	p.ctx = ctx
+
+	return p
+}
+
+/*
+Pages returns a stream of pages.
+*/
+func (p *Pagination[T]) Pages() *Stream[Page[T]] {
+	var (
+		number = 0
+		offset = 0
+		done   = false
+	)
+	if p.ctx != nil {
+		p.builder.Context(p.ctx)
+	}
+
+	return NewStream(p.ctx, func() (Page[T], error) {
+		// Check limits
+		if done || (p.limit > 0 && number >= p.limit) {
+			return Page[T]{}, io.EOF
+		}
+		if number == 0 {
+			p.strategy.First(p.builder)
+		}
+		// Fetch page
+		resp := p.builder.Do().Success()
+		if resp.Error() != nil {
+			return Page[T]{}, resp.Error()
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return Page[T]{}, err
+		}
+		// Decode items
+		items, err := paginateItems[T](body, p.strategy)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		number++
+		// Prepare next page
+		done = len(items) == 0 || !p.strategy.Next(p.builder, &PageResponse{
+			Response: resp.Response,
+			Body:     body,
+			Items:    len(items),
+			Offset:   offset,
+		})
+		offset += len(items)
+
+		return Page[T]{Number: number, Items: items, Header: resp.Header}, nil
+	}, nil)
+}
+
+/*
+Items returns a stream of items across all pages.
+*/
+func (p *Pagination[T]) Items() *Stream[T] {
+	var (
+		pages = p.Pages()
+		queue = []T{}
+	)
+
+	return NewStream(nil, func() (T, error) {
+		for len(queue) == 0 {
+			if !pages.Next() {
+				var zero T
+				return zero, errOr(pages.Err(), io.EOF)
+			}
+			queue = pages.Value().Items
+		}
+		item := queue[0]
+		queue = queue[1:]
+
+		return item, nil
+	}, pages.Close)
+}
+
+/*
+All collects all items across all pages.
+*/
+func (p *Pagination[T]) All() ([]T, error) {
+	return p.Items().Collect()
+}
+
+/*
+Paginate creates a pagination iterator over a paged API.
+
+Usage:
+
+	items, err := httpx.Paginate[Repo](
+		httpx.Request("GET", "https://api.github.com/orgs/golang/repos"),
+		&httpx.LinkPagination{},
+	).Limit(10).Context(ctx).All()
+
+	// or, iterate over pages
+	pages := httpx.Paginate[Item](builder, &httpx.CursorPagination{Param: "cursor", Field: "meta.next"}).Pages()
+	defer pages.Close()
+	for pages.Next() {
+		page := pages.Value()
+	}
+*/
+func Paginate[T any](b *RequestBuilder, strategy PageStrategy) *Pagination[T] {
+	return &Pagination[T]{
+		builder:  b,
+		strategy: strategy,
+	}
+}
+
+// paginateItems decodes page items from body.
+func paginateItems[T any](body []byte, strategy PageStrategy) ([]T, error) {
+	raw := json.RawMessage(body)
+	// Strategy items path
+	if pather, ok := strategy.(interface{ ItemsPath() string }); ok && pather.ItemsPath() != "" {
+		value, ok := jsonPath(body, pather.ItemsPath())
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPageItems, pather.ItemsPath())
+		}
+		raw = value
+	}
+	// Lookup common fields, if body is an object
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		found := false
+		for _, field := range []string{"items", "data", "results"} {
+			if value, ok := jsonPath(raw, field); ok {
+				raw, found = value, true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrPageItems
+		}
+	}
+	// Decode items
+	items := []T{}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// jsonPath extracts a raw value from json by dot-separated path.
+func jsonPath(body []byte, path string) (json.RawMessage, bool) {
+	value := json.RawMessage(body)
+	for _, key := range strings.Split(path, ".") {
+		object := map[string]json.RawMessage{}
+		if err := json.Unmarshal(value, &object); err != nil {
+			return nil, false
+		}
+		if value = object[key]; value == nil {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// Strategies
+
+/*
+LinkPagination follows RFC 5988 "Link" header with rel="next".
+*/
+type LinkPagination struct {
+	// Path to items in json body (optional)
+	Path string
+}
+
+// ItemsPath returns items path.
+func (s *LinkPagination) ItemsPath() string {
+	return s.Path
+}
+
+// First does nothing, builder url is used as is.
+func (s *LinkPagination) First(b *RequestBuilder) {}
+
+// Next follows the next link, if exists.
+func (s *LinkPagination) Next(b *RequestBuilder, page *PageResponse) bool {
+	next := linkNext(page.Response.Header.Values("Link"))
+	if next == "" {
+		return false
+	}
+	href, err := b.href.Parse(next)
+	if err != nil {
+		return false
+	}
+	b.href = href
+
+	return true
+}
+
+// linkNext finds rel="next" url in Link header values.
+func linkNext(values []string) string {
+	for _, value := range values {
+		for _, link := range linkSplit(value, ',') {
+			// Link must start with <url>
+			link = strings.TrimSpace(link)
+			end := strings.IndexByte(link, '>')
+			if !strings.HasPrefix(link, "<") || end < 0 {
+				continue
+			}
+			href := link[1:end]
+			for _, param := range linkSplit(link[end+1:], ';') {
+				name, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return href
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// linkSplit splits Link header value by separator,
+// ignoring separators inside of <url> and quoted strings.
+func linkSplit(value string, sep byte) []string {
+	var (
+		parts  []string
+		start  = 0
+		angle  = false
+		quoted = false
+	)
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case angle:
+			angle = c != '>'
+		case c == '<':
+			angle = true
+		case c == '"':
+			quoted = true
+		case c == sep:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+/*
+CursorPagination reads a cursor from json body field
+and passes it as a query parameter to the next page request.
+Empty or null cursor means there are no more pages.
+*/
+type CursorPagination struct {
+	// Param is a query parameter name for cursor
+	Param string
+	// Field is a dot-separated path to cursor in json body
+	Field string
+	// Path to items in json body (optional)
+	Path string
+}
+
+// ItemsPath returns items path.
+func (s *CursorPagination) ItemsPath() string {
+	return s.Path
+}
+
+// First does nothing, the first page is requested without cursor.
+func (s *CursorPagination) First(b *RequestBuilder) {}
+
+// Next sets cursor query parameter, if body contains a cursor.
+func (s *CursorPagination) Next(b *RequestBuilder, page *PageResponse) bool {
+	raw, ok := jsonPath(page.Body, s.Field)
+	if !ok {
+		return false
+	}
+	// Cursor might be a string or a number
+	var cursor any
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor == nil {
+		return false
+	}
+	value := strings.Trim(string(raw), `"`)
+	if str, ok := cursor.(string); ok {
+		value = str
+	}
+	if value == "" {
+		return false
+	}
+	b.Query(s.Param, value)
+
+	return true
+}
+
+/*
+OffsetPagination passes offset and limit query parameters.
+Page with less than Limit items is considered as the last one.
+Offset is tracked by the iterator, so a strategy value might be shared.
+*/
+type OffsetPagination struct {
+	// OffsetParam is a query parameter name for offset. Defaults to "offset"
+	OffsetParam string
+	// LimitParam is a query parameter name for limit. Defaults to "limit"
+	LimitParam string
+	// Limit is a page size
+	Limit int
+	// Path to items in json body (optional)
+	Path string
+}
+
+// ItemsPath returns items path.
+func (s *OffsetPagination) ItemsPath() string {
+	return s.Path
+}
+
+// First sets initial offset and limit.
+func (s *OffsetPagination) First(b *RequestBuilder) {
+	s.set(b, 0)
+}
+
+// Next moves offset forward.
+func (s *OffsetPagination) Next(b *RequestBuilder, page *PageResponse) bool {
+	if page.Items < s.Limit {
+		return false
+	}
+	s.set(b, page.Offset+page.Items)
+
+	return true
+}
+
+// set writes offset and limit query parameters.
+func (s *OffsetPagination) set(b *RequestBuilder, offset int) {
+	offsetparam, limitparam := s.OffsetParam, s.LimitParam
+	if offsetparam == "" {
+		offsetparam = "offset"
+	}
+	if limitparam == "" {
+		limitparam = "limit"
+	}
+	b.Query(offsetparam, strconv.Itoa(offset))
+	b.Query(limitparam, strconv.Itoa(s.Limit))
+}
@@ -0,0 +1,138 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// paginateTestItems is a total number of items, served by paginateTestServer.
+const paginateTestItems = 25
+
+// paginateTestServer serves numbered items with link, cursor and offset pagination.
+func paginateTestServer() (*httptest.Server, *int32) {
+	requests := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		query := r.URL.Query()
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		if cursor := query.Get("cursor"); cursor != "" {
+			offset, _ = strconv.Atoi(cursor)
+		}
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		if limit == 0 {
+			limit = 10
+		}
+		items := []int{}
+		for i := offset; i < offset+limit && i < paginateTestItems; i++ {
+			items = append(items, i)
+		}
+		next := offset + len(items)
+		body := map[string]any{"data": items, "meta": map[string]any{"next": nil}}
+		if next < paginateTestItems {
+			body["meta"] = map[string]any{"next": strconv.Itoa(next)}
+			w.Header().Add("Link", fmt.Sprintf(`<%s/items?offset=0&limit=%d>; rel="first", <%s/items?offset=%d&limit=%d>; rel="next"`,
+				"http://"+r.Host, limit, "http://"+r.Host, next, limit))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body) //nolint:errcheck
+	}))
+
+	return server, requests
+}
+
+func TestPaginateStrategies(t *testing.T) {
+	server, _ := paginateTestServer()
+	defer server.Close()
+
+	strategies := map[string]PageStrategy{
+		"link":   &LinkPagination{},
+		"cursor": &CursorPagination{Param: "cursor", Field: "meta.next"},
+		"offset": &OffsetPagination{Limit: 10},
+	}
+	for name, strategy := range strategies {
+		// Run twice to ensure strategy value is reusable
+		for run := 0; run < 2; run++ {
+			items, err := Paginate[int](Request("GET", server.URL+"/items"), strategy).All()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if len(items) != paginateTestItems {
+				t.Fatalf("%s: expected %d items, got %d", name, paginateTestItems, len(items))
+			}
+			for i, item := range items {
+				if item != i {
+					t.Fatalf("%s: expected item %d, got %d", name, i, item)
+				}
+			}
+		}
+	}
+}
+
+func TestPaginateLimit(t *testing.T) {
+	server, requests := paginateTestServer()
+	defer server.Close()
+
+	pages := Paginate[int](Request("GET", server.URL+"/items"), &LinkPagination{}).Limit(2).Pages()
+	defer pages.Close()
+	numbers := []int{}
+	for pages.Next() {
+		numbers = append(numbers, pages.Value().Number)
+	}
+	if pages.Err() != nil {
+		t.Fatal(pages.Err())
+	}
+	if len(numbers) != 2 || numbers[0] != 1 || numbers[1] != 2 {
+		t.Fatalf("expected pages [1 2], got %v", numbers)
+	}
+	if n := atomic.LoadInt32(requests); n != 2 {
+		t.Fatalf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestPaginateCanceled(t *testing.T) {
+	server, requests := paginateTestServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	items := Paginate[int](Request("GET", server.URL+"/items"), &OffsetPagination{Limit: 10}).Context(ctx).Items()
+	defer items.Close()
+	if !items.Next() {
+		t.Fatal(items.Err())
+	}
+	cancel()
+	for items.Next() {
+		// Drain already fetched page
+	}
+	if !errors.Is(items.Err(), context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", items.Err())
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Fatalf("expected 1 request, got %d", n)
+	}
+}
+
+func TestLinkNext(t *testing.T) {
+	tests := []struct {
+		values []string
+		next   string
+	}{
+		{[]string{`<https://a.com/?page=2>; rel="next"`}, "https://a.com/?page=2"},
+		{[]string{`<https://a.com/?ids=1,2,3&page=2>; rel="next"`}, "https://a.com/?ids=1,2,3&page=2"},
+		{[]string{`<https://a.com/?page=1>; rel="prev", <https://a.com/?a=1,2>; title="x, y"; rel="next last"`}, "https://a.com/?a=1,2"},
+		{[]string{`<https://a.com/?page=1>; rel="prev"`, `<https://a.com/?page=3>; REL=next`}, "https://a.com/?page=3"},
+		{[]string{`<https://a.com/?page=1>; rel="prev"`}, ""},
+		{[]string{`https://a.com/; rel="next"`}, ""},
+	}
+	for _, test := range tests {
+		if next := linkNext(test.values); next != test.next {
+			t.Fatalf("%v: expected %q, got %q", test.values, test.next, next)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package httpx
 
 import (
+	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -20,7 +23,7 @@ Usage:
 */
 func (p Path) Get(index int) string {
 	tokens := p.Tokens()
-	if len(tokens) < index {
+	if index < 0 || index >= len(tokens) {
 		return ""
 	}
 
@@ -101,6 +104,61 @@ func (p Path) GetAfterWithIndex(token string) (string, int) {
 	return "", -1
 }
 
+/*
+Match checks path against a pattern and extracts path parameters.
+Pattern segments might be literal, "{name}" for a single segment parameter,
+or "{name...}" for the rest of the path (allowed only as the last segment).
+
+Usage:
+
+	path := httpx.Path("/users/42/posts/2022/hello")
+	params, ok := path.Match("/users/{id}/posts/{slug...}") // true
+	params.Get("slug") // "2022/hello"
+	params.Int("id") // 42, nil
+*/
+func (p Path) Match(pattern string) (Params, bool) {
+	var (
+		tokens   = p.Tokens()
+		segments = Path(pattern).Tokens()
+		params   = Params{}
+	)
+
+	for i, segment := range segments {
+		kind, name := pathSegment(segment)
+		// Wildcard consumes the rest of the path
+		if kind == pathSegmentWildcard {
+			if i >= len(tokens) {
+				return nil, false
+			}
+			params[name] = strings.Join(tokens[i:], "/")
+
+			return params, true
+		}
+		// Path is shorter than pattern
+		if i >= len(tokens) {
+			return nil, false
+		}
+		// Match segment
+		switch kind {
+		case pathSegmentParam:
+			if tokens[i] == "" {
+				return nil, false
+			}
+			params[name] = tokens[i]
+		default:
+			if tokens[i] != segment {
+				return nil, false
+			}
+		}
+	}
+	// Path is longer than pattern
+	if len(tokens) != len(segments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
 /*
 Tokens returns path tokens.
 
@@ -124,3 +182,108 @@ Usage:
 func PathFromTokens(tokens []string) Path {
 	return Path("/" + strings.Join(tokens, "/"))
 }
+
+// Path segment kinds.
+const (
+	pathSegmentStatic = iota
+	pathSegmentParam
+	pathSegmentWildcard
+)
+
+// pathSegment detects a pattern segment kind and parameter name.
+func pathSegment(segment string) (int, string) {
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return pathSegmentStatic, ""
+	}
+
+	name := segment[1 : len(segment)-1]
+	if strings.HasSuffix(name, "...") {
+		return pathSegmentWildcard, strings.TrimSuffix(name, "...")
+	}
+
+	return pathSegmentParam, name
+}
+
+/*
+Params holds path parameters, extracted with Path.Match or Router.
+It provides typed getters for parameter values.
+*/
+type Params map[string]string
+
+/*
+Get returns a parameter value, or empty string if missing.
+*/
+func (p Params) Get(name string) string {
+	return p[name]
+}
+
+/*
+Int returns a parameter value, parsed as int.
+*/
+func (p Params) Int(name string) (int, error) {
+	v, err := strconv.Atoi(p[name])
+	if err != nil {
+		return 0, fmt.Errorf("path param %s: %w", name, err)
+	}
+
+	return v, nil
+}
+
+/*
+Int64 returns a parameter value, parsed as int64.
+*/
+func (p Params) Int64(name string) (int64, error) {
+	v, err := strconv.ParseInt(p[name], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("path param %s: %w", name, err)
+	}
+
+	return v, nil
+}
+
+/*
+Float64 returns a parameter value, parsed as float64.
+*/
+func (p Params) Float64(name string) (float64, error) {
+	v, err := strconv.ParseFloat(p[name], 64)
+	if err != nil {
+		return 0, fmt.Errorf("path param %s: %w", name, err)
+	}
+
+	return v, nil
+}
+
+/*
+Bool returns a parameter value, parsed as bool.
+*/
+func (p Params) Bool(name string) (bool, error) {
+	v, err := strconv.ParseBool(p[name])
+	if err != nil {
+		return false, fmt.Errorf("path param %s: %w", name, err)
+	}
+
+	return v, nil
+}
+
+/*
+Unmarshal decodes parameters into a struct, using "path" struct tag.
+Tag format is the same as for Query.
+
+Usage:
+
+	var target struct {
+		ID int `path:"id"`
+	}
+	err := params.Unmarshal(&target)
+*/
+func (p Params) Unmarshal(target any) error {
+	ob := reflect.ValueOf(target)
+	if ob.Kind() == reflect.Ptr {
+		ob = ob.Elem()
+	}
+	if ob.Kind() != reflect.Struct {
+		return ErrUnmarshalTarget
+	}
+
+	return codecDecodeStruct(bindPathValues(p), "path", "", ob)
+}
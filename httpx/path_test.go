@@ -0,0 +1,75 @@
+package httpx
+
+import "testing"
+
+func TestPathGet(t *testing.T) {
+	path := Path("/foo/bar/baz")
+	tests := map[int]string{-1: "", 0: "foo", 2: "baz", 3: "", 10: ""}
+	for index, expected := range tests {
+		if token := path.Get(index); token != expected {
+			t.Fatalf("index %d: expected %q, got %q", index, expected, token)
+		}
+	}
+}
+
+func TestPathMatch(t *testing.T) {
+	tests := []struct {
+		path    string
+		pattern string
+		ok      bool
+		params  Params
+	}{
+		{"/users/42", "/users/{id}", true, Params{"id": "42"}},
+		{"/users/", "/users/{id}", false, nil},
+		{"/users/42/posts", "/users/{id}", false, nil},
+		{"/users", "/users/{id}", false, nil},
+		{"/users/42", "/posts/{id}", false, nil},
+		{"/users/42/posts/2022/hello", "/users/{id}/posts/{slug...}", true, Params{"id": "42", "slug": "2022/hello"}},
+		{"/users/42/posts/hello", "/users/{id}/posts/{slug...}", true, Params{"id": "42", "slug": "hello"}},
+		{"/users/42/posts", "/users/{id}/posts/{slug...}", false, nil},
+		{"/static", "/static", true, Params{}},
+	}
+	for _, test := range tests {
+		params, ok := Path(test.path).Match(test.pattern)
+		if ok != test.ok {
+			t.Fatalf("%s against %s: expected match %t", test.path, test.pattern, test.ok)
+		}
+		if len(params) != len(test.params) {
+			t.Fatalf("%s against %s: expected params %v, got %v", test.path, test.pattern, test.params, params)
+		}
+		for name, value := range test.params {
+			if params.Get(name) != value {
+				t.Fatalf("%s against %s: expected %s=%s, got %s", test.path, test.pattern, name, value, params.Get(name))
+			}
+		}
+	}
+}
+
+func TestParamsTyped(t *testing.T) {
+	params, _ := Path("/items/42/1.5/true/abc").Match("/items/{int}/{float}/{bool}/{str}")
+	if v, err := params.Int("int"); err != nil || v != 42 {
+		t.Fatalf("expected 42, got %d, %v", v, err)
+	}
+	if v, err := params.Int64("int"); err != nil || v != 42 {
+		t.Fatalf("expected 42, got %d, %v", v, err)
+	}
+	if v, err := params.Float64("float"); err != nil || v != 1.5 {
+		t.Fatalf("expected 1.5, got %f, %v", v, err)
+	}
+	if v, err := params.Bool("bool"); err != nil || !v {
+		t.Fatalf("expected true, got %t, %v", v, err)
+	}
+	if _, err := params.Int("str"); err == nil {
+		t.Fatal("expected parse error")
+	}
+	var target struct {
+		ID  int    `path:"int"`
+		Str string `path:"str"`
+	}
+	if err := params.Unmarshal(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.ID != 42 || target.Str != "abc" {
+		t.Fatalf("expected unmarshaled params, got %+v", target)
+	}
+}
@@ -4,32 +4,53 @@ import (
 	"errors"
 	"net/url"
 	"reflect"
-	"strconv"
-	"strings"
 )
 
-var ErrUnmarshalTarget = errors.New("failed to encode form values to struct, non struct type is given")
+var (
+	ErrUnmarshalTarget = errors.New("failed to encode form values to struct, non struct type is given")
+	ErrMarshalSource   = errors.New("failed to encode struct to query values, non struct/map type is given")
+)
 
 /*
 Query type is a wrapper for url.Values.
 It provides a few useful extra methods to operate with query.
+
+Both Marshal and Unmarshal are using the same "query" struct tag,
+so encoding and decoding are symmetric.
+Tag format is `query:"name,option,option"` with these options:
+
+  - omitempty - skip zero values on encoding.
+  - comma - encode/decode lists as comma-separated value, instead of repeated keys.
+  - default=value - value to use on decoding if key is missing.
+    Must be the last option, because it may contain commas.
+    For lists, default value is split by comma.
+
+Nested structs and string-keyed maps are using bracket notation,
+like "filter[name]=foo&filter[range][min]=1".
+Pointers, time.Time, time.Duration and encoding.TextMarshaler/TextUnmarshaler
+implementations are supported as well.
+Untagged fields are ignored, except embedded structs, which are flattened.
 */
 type Query url.Values
 
-//nolint:all
 /*
 Unmarshal helps to parse url.Values into a struct.
-Slightly modified version of github.com/knadh/querytostruct
+If some value can't be parsed, it returns *FieldError
+with a failing field name.
 
 Example:
 
 	var target struct {
 		Foo string `query:"foo"`
-		Bar int `query:"bar"`
+		Bar int `query:"bar,default=10"`
+		Baz []string `query:"baz,comma"`
+		Filter struct {
+			Since time.Time `query:"since"`
+		} `query:"filter"`
 	}
 
-	q, _ := url.ParseQuery("foo=asdqwe&bar=123")
-	kyoto.Query(q).Unmarshal(&target)
+	q, _ := url.ParseQuery("foo=asdqwe&baz=a,b&filter[since]=2022-01-01T00:00:00Z")
+	err := httpx.Query(q).Unmarshal(&target)
 */
 func (q Query) Unmarshal(target any) error {
 	// Get target reflection value
@@ -37,88 +58,42 @@ func (q Query) Unmarshal(target any) error {
 	if ob.Kind() == reflect.Ptr {
 		ob = ob.Elem()
 	}
-
 	// Validate value is a struct
 	if ob.Kind() != reflect.Struct {
 		return ErrUnmarshalTarget
 	}
+	// Decode
+	return codecDecodeStruct(q, "query", "", ob)
+}
 
-	// Go through every field in the struct and look for it in the query map
-	for i := 0; i < ob.NumField(); i++ {
-		f := ob.Field(i)
-		if f.IsValid() && f.CanSet() {
-			tag := ob.Type().Field(i).Tag.Get("query")
-			if tag == "" || tag == "-" {
-				continue
-			}
-
-			// Got a struct field with a tag.
-			// If that field exists in the arg and convert its type.
-			// Tags are of the type `tagname,attribute`
-			tag = strings.Split(tag, ",")[0]
-			if _, ok := q[tag]; !ok {
-				continue
-			}
-
-			// The struct field is a slice type.
-			if f.Kind() == reflect.Slice {
-				var (
-					vals    = q[tag]
-					numVals = len(vals)
-				)
-
-				// Make a slice.
-				sl := reflect.MakeSlice(f.Type(), numVals, numVals)
+/*
+Marshal encodes a given struct (or string-keyed map) into query values.
+Existing values under the same keys are replaced.
+If some value can't be encoded, it returns *FieldError
+with a failing field name.
 
-				// If it's a []byte slice (=[]uint8), assign here.
-				if f.Type().Elem().Kind() == reflect.Uint8 {
-					br := url.Values(q).Get(tag)
-					b := make([]byte, len(br))
-					copy(b, br)
-					f.SetBytes(b)
-					continue
-				}
+Example:
 
-				// Iterate through args and assign values
-				// to each item in the slice.
-				for i, v := range vals {
-					querySetVal(sl.Index(i), v)
-				}
-				f.Set(sl)
-			} else {
-				querySetVal(f, url.Values(q).Get(tag))
-			}
-		}
+	q := httpx.Query{}
+	err := q.Marshal(struct {
+		Foo string `query:"foo,omitempty"`
+		Baz []int `query:"baz"`
+	}{Baz: []int{1, 2}})
+	url.Values(q).Encode() // baz=1&baz=2
+*/
+func (q Query) Marshal(source any) error {
+	// Get source reflection value
+	ob := reflect.Indirect(reflect.ValueOf(source))
+	if !ob.IsValid() {
+		return nil
 	}
-	return nil
-}
-
-//nolint:all
-func querySetVal(f reflect.Value, val string) bool {
-	switch f.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if v, err := strconv.ParseInt(val, 10, 0); err == nil {
-			f.SetInt(v)
-		}
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if v, err := strconv.ParseUint(val, 10, 0); err == nil {
-			f.SetUint(v)
-		}
-	case reflect.Float32:
-		if v, err := strconv.ParseFloat(val, 32); err == nil {
-			f.SetFloat(v)
-		}
-	case reflect.Float64:
-		if v, err := strconv.ParseFloat(val, 64); err == nil {
-			f.SetFloat(v)
-		}
-	case reflect.String:
-		f.SetString(val)
-	case reflect.Bool:
-		b, _ := strconv.ParseBool(val)
-		f.SetBool(b)
+	// Encode
+	switch ob.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		return codecEncodeStruct(q, "query", "", ob)
+	case reflect.Map:
+		return codecEncodeValue(q, "query", "", codecTag{}, ob)
 	default:
-		return false
+		return ErrMarshalSource
 	}
-	return true
 }
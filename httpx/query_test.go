@@ -0,0 +1,88 @@
+package httpx
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type queryTestFilter struct {
+	Name  string            `query:"name"`
+	Since *time.Time        `query:"since"`
+	Meta  map[string]string `query:"meta"`
+}
+
+type queryTestParams struct {
+	Foo    string          `query:"foo"`
+	Limit  int             `query:"limit,default=10"`
+	Tags   []string        `query:"tags,comma"`
+	IDs    []int           `query:"id"`
+	Point  [2]float64      `query:"point,comma"`
+	Empty  string          `query:"empty,omitempty"`
+	Wait   time.Duration   `query:"wait"`
+	Filter queryTestFilter `query:"filter"`
+}
+
+func TestQueryRoundTrip(t *testing.T) {
+	since := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := queryTestParams{
+		Foo:   "bar",
+		Limit: 5,
+		Tags:  []string{"a", "b"},
+		IDs:   []int{1, 2},
+		Point: [2]float64{1.5, -2},
+		Wait:  time.Second,
+		Filter: queryTestFilter{
+			Name:  "baz",
+			Since: &since,
+			Meta:  map[string]string{"k": "v"},
+		},
+	}
+
+	query := Query{}
+	if err := query.Marshal(source); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := url.Values(query).Encode()
+	expected := "filter%5Bmeta%5D%5Bk%5D=v&filter%5Bname%5D=baz&filter%5Bsince%5D=2022-01-01T00%3A00%3A00Z" +
+		"&foo=bar&id=1&id=2&limit=5&point=1.5%2C-2&tags=a%2Cb&wait=1s"
+	if encoded != expected {
+		t.Fatalf("unexpected encoding: %s", encoded)
+	}
+
+	var target queryTestParams
+	if err := query.Unmarshal(&target); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(source, target) {
+		t.Fatalf("round trip mismatch: %+v != %+v", source, target)
+	}
+}
+
+func TestQueryUnmarshalDefaultsAndErrors(t *testing.T) {
+	var target queryTestParams
+	if err := (Query{}).Unmarshal(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Limit != 10 {
+		t.Fatalf("expected default limit, got %d", target.Limit)
+	}
+
+	err := Query(url.Values{"filter[since]": {"yesterday"}}).Unmarshal(&target)
+
+	var ferr *FieldError
+	if !errors.As(err, &ferr) {
+		t.Fatalf("expected field error, got %v", err)
+	}
+	if ferr.Field != "Filter.Since" || ferr.Key != "filter[since]" {
+		t.Fatalf("unexpected field error: %v", ferr)
+	}
+
+	err = Query(url.Values{"point": {"1,2,3"}}).Unmarshal(&target)
+	if !errors.Is(err, ErrCodecArrayLength) {
+		t.Fatalf("expected array length error, got %v", err)
+	}
+}
@@ -1,8 +1,10 @@
 package httpx
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,11 +16,19 @@ import (
 	"github.com/yznts/zen/v3/jsonx"
 )
 
+// RetryBackoff is an initial delay between retry attempts, doubled after each attempt.
+// Zero means retrying immediately.
+var RetryBackoff = 100 * time.Millisecond
+
+// RetryMaxBackoff limits a delay between retry attempts.
+var RetryMaxBackoff = 5 * time.Second
+
 /*
 RequestBuilder provides set of chainable functions
 to build a request and execute it.
 */
 type RequestBuilder struct {
+	ctx     context.Context //nolint:containedctx
 	method  string
 	href    *url.URL
 	body    io.Reader
@@ -26,6 +36,12 @@ type RequestBuilder struct {
 	retry   int
 	timeout time.Duration
 
+	compress   string
+	budget     time.Duration
+	budgeted   bool
+	hedgeAfter time.Duration
+	hedgeMax   int
+
 	client *http.Client
 }
 
@@ -84,13 +100,22 @@ func (r *RequestBuilder) QueryValues(values url.Values) *RequestBuilder {
 
 /*
 QueryStruct sets a query values with a given object.
-It uses conv.Map to extract values,
-then acts in the same way as QueryMapFmt.
+It uses Query.Marshal to extract values (check Query for tag format),
+then replaces existing query values under the same keys.
 If something goes wrong with marshalling, it panics.
 */
 func (r *RequestBuilder) QueryStruct(values any) *RequestBuilder {
-	data := conv.Map(values)
-	r.QueryMapFmt(data)
+	data := Query{}
+	if err := data.Marshal(values); err != nil {
+		panic(err)
+	}
+
+	query := r.href.Query()
+	for k, v := range data {
+		query[k] = v
+	}
+
+	r.href.RawQuery = query.Encode()
 
 	return r
 }
@@ -236,6 +261,30 @@ func (r *RequestBuilder) Client(client *http.Client) *RequestBuilder {
 	return r
 }
 
+/*
+Retry sets a number of extra attempts on request error.
+Only transport errors are retried, responses with any status code are returned as-is.
+Attempts are delayed with exponential backoff and jitter,
+starting from RetryBackoff up to RetryMaxBackoff.
+Waiting stops on context cancellation (or budget exhaustion).
+Body is buffered to be resent.
+*/
+func (r *RequestBuilder) Retry(n int) *RequestBuilder {
+	r.retry = n
+
+	return r
+}
+
+/*
+Context sets a request context.
+Context is used for request cancellation and streams closing.
+*/
+func (r *RequestBuilder) Context(ctx context.Context) *RequestBuilder {
+	r.ctx = ctx
+
+	return r
+}
+
 // Closers
 
 /*
@@ -251,19 +300,79 @@ func (r *RequestBuilder) Do() *ResponseWrapper {
 	if r.timeout != 0 {
 		r.client.Timeout = r.timeout
 	}
-	// Make request with retry
+	// Apply deadline budget, context is released on body close
+	ctx, cancel := r.budgetContext()
+	// Build request, buffer body for retries
+	request := r.Build().WithContext(ctx)
+	if r.retry > 0 {
+		if _, err := readBody(request); err != nil {
+			cancel()
+			return Response(nil, err)
+		}
+	}
+	// Make request with retry (at least one attempt)
 	var response *ResponseWrapper
-	for i := 0; i < r.retry; i++ {
-		response = Response(r.client.Do(r.Build()))
+	for i := 0; i <= r.retry; i++ {
+		// Back off
+		if i > 0 && !sleep(ctx, backoff(i)) {
+			break
+		}
+		// Rewind body
+		if i > 0 && request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				cancel()
+				return Response(nil, err)
+			}
+			request = request.Clone(request.Context())
+			request.Body = body
+		}
+		// Mark attempt for hooks (see Trace)
+		attempt := withAttempt(request, i)
+		// Pass remaining budget, it's shrinking with each attempt
+		r.budgetHeader(attempt)
+		if r.hedgeMax > 1 {
+			response = Response(r.hedge(attempt))
+		} else {
+			response = Response(r.client.Do(attempt))
+		}
+		response.client = r.client
 		// Return success response
 		if response.Error() == nil {
+			response.Body = &bodyCloser{ReadCloser: response.Body, onclose: cancel}
 			return response
 		}
 	}
+	cancel()
 	// Return last failed response
 	return response
 }
 
+// backoff returns a delay before a given retry attempt (1-based).
+func backoff(attempt int) time.Duration {
+	delay := RetryBackoff
+	for i := 1; i < attempt && delay < RetryMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > RetryMaxBackoff {
+		delay = RetryMaxBackoff
+	}
+	// Jitter, 50-100% of delay
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)) //nolint:gosec
+}
+
+// sleep waits for a given delay, returns false on context cancellation.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 /*
 Async wraps a request execution (Do) with an async.Future.
 */
@@ -277,14 +386,30 @@ func (r *RequestBuilder) Async() *async.Future[*ResponseWrapper] {
 
 /*
 Build composes provided parameters into *http.Request.
+Request gets a copy of builder headers, so builder might be reused.
 */
 func (r *RequestBuilder) Build() *http.Request {
-	request, err := http.NewRequest(r.method, r.href.String(), r.body)
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	body := r.body
+	if r.compress != "" && r.body != nil {
+		body = r.compressBody()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, r.method, r.href.String(), body)
 	if err != nil {
 		panic(err)
 	}
 
-	request.Header = r.header
+	// Builder headers are kept intact for further builds
+	request.Header = http.Header(r.header).Clone()
+	if r.compress != "" && r.body != nil {
+		request.Header.Set("Content-Encoding", r.compress)
+	}
+	r.budgetHeader(request)
 
 	return request
 }
@@ -0,0 +1,151 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type requestTestTransport struct {
+	attempts int
+	err      error
+}
+
+func (t *requestTestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.attempts++
+
+	return nil, t.err
+}
+
+func TestRequestDoWithoutRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok")) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	response := Request("GET", server.URL).Do()
+	if response == nil {
+		t.Fatal("expected a response without retry")
+	}
+	if err := response.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestDoRetryAttempts(t *testing.T) {
+	for _, retry := range []int{0, 2} {
+		transport := &requestTestTransport{err: errors.New("unreachable")}
+		builder := Request("GET", "http://example.invalid").Client(&http.Client{Transport: transport})
+		builder.retry = retry
+		if err := builder.Do().Error(); err == nil {
+			t.Fatal("expected an error")
+		}
+		if transport.attempts != retry+1 {
+			t.Fatalf("retry %d: expected %d attempts, got %d", retry, retry+1, transport.attempts)
+		}
+	}
+}
+
+func TestRequestDoRetryBackoff(t *testing.T) {
+	defer func(backoff time.Duration) { RetryBackoff = backoff }(RetryBackoff)
+	RetryBackoff = 40 * time.Millisecond
+
+	transport := &requestTestTransport{err: errors.New("unreachable")}
+	start := time.Now()
+	Request("GET", "http://example.invalid").
+		Client(&http.Client{Transport: transport}).
+		Retry(2).
+		Do()
+	// 20-40ms and 40-80ms delays with jitter
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Fatalf("expected retries to back off, got %s", elapsed)
+	}
+	if transport.attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", transport.attempts)
+	}
+}
+
+func TestRequestDoRetryCanceled(t *testing.T) {
+	defer func(backoff time.Duration) { RetryBackoff = backoff }(RetryBackoff)
+	RetryBackoff = time.Minute
+
+	transport := &requestTestTransport{err: errors.New("unreachable")}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := Request("GET", "http://example.invalid").
+		Context(ctx).
+		Client(&http.Client{Transport: transport}).
+		Retry(2).
+		Do().
+		Error()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected backoff to stop on context cancellation, got %s", elapsed)
+	}
+	if transport.attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", transport.attempts)
+	}
+}
+
+// budgetTestTransport records budget headers and fails first attempts.
+type budgetTestTransport struct {
+	budgets []string
+	fails   int
+}
+
+func (t *budgetTestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.budgets = append(t.budgets, req.Header.Get(BudgetHeader))
+	if len(t.budgets) <= t.fails {
+		return nil, errors.New("unreachable")
+	}
+
+	return &http.Response{StatusCode: 200, Body: http.NoBody, Request: req}, nil
+}
+
+func TestRequestBudgetPerAttempt(t *testing.T) {
+	defer func(backoff time.Duration) { RetryBackoff = backoff }(RetryBackoff)
+	RetryBackoff = 100 * time.Millisecond
+
+	transport := &budgetTestTransport{fails: 1}
+	err := Request("GET", "http://example.invalid").
+		Client(&http.Client{Transport: transport}).
+		Budget(time.Second).
+		Retry(1).
+		Do().
+		Error()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.budgets) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(transport.budgets))
+	}
+	first, _ := strconv.Atoi(transport.budgets[0])
+	second, _ := strconv.Atoi(transport.budgets[1])
+	if first <= 0 || second >= first {
+		t.Fatalf("expected budget to shrink between attempts, got %v", transport.budgets)
+	}
+}
+
+func TestRequestBuildKeepsHeader(t *testing.T) {
+	builder := Request("GET", "http://example.invalid").
+		Header("X-Test", "1").
+		Budget(time.Second)
+	request := builder.Build()
+	if request.Header.Get(BudgetHeader) == "" {
+		t.Fatal("expected budget header to be set")
+	}
+	request.Header.Set("X-Other", "1")
+	if _, ok := builder.header[BudgetHeader]; ok {
+		t.Fatal("expected budget header not to leak into builder")
+	}
+	if next := builder.Build(); next.Header.Get("X-Other") != "" || next.Header.Get("X-Test") != "1" {
+		t.Fatalf("expected builder headers to be kept intact, got %v", next.Header)
+	}
+}
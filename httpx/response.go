@@ -18,7 +18,8 @@ It provides a set of functions for a chained response processing.
 type ResponseWrapper struct {
 	*http.Response
 
-	err error
+	err    error
+	client *http.Client
 }
 
 /*
@@ -53,17 +54,16 @@ func (r *ResponseWrapper) Clear() *ResponseWrapper {
 }
 
 /*
-Debug prints the response to stdout.
+Debug writes the response dump into a provided sink
+(DefaultDebugSink, which writes to stderr, if not provided).
 If something goes wrong during dump, chain execution will be stopped.
 Returns wrapper for chaining.
 */
-func (r *ResponseWrapper) Debug() *ResponseWrapper {
+func (r *ResponseWrapper) Debug(sink ...DebugSink) *ResponseWrapper {
 	// Check error status
 	if r.err != nil {
 		return r
 	}
-	// Print url
-	println(r.Request.URL.String()) //nolint:forbidigo
 	// Dump raw response
 	dump, err := httputil.DumpResponse(r.Response, true)
 	// If we got an error, prevent further chain execution
@@ -73,8 +73,13 @@ func (r *ResponseWrapper) Debug() *ResponseWrapper {
 		// Return wrapper
 		return r
 	}
-	// Print raw response
-	println(string(dump)) //nolint:forbidigo
+	// Write dump
+	if len(sink) == 0 {
+		sink = append(sink, DefaultDebugSink)
+	}
+	for _, s := range sink {
+		s.Debug(r.Response, dump)
+	}
 	// Return wrapper
 	return r
 }
@@ -100,14 +105,22 @@ func (r *ResponseWrapper) Success() *ResponseWrapper {
 
 /*
 Text reads response body as a text.
+Encoded body (see RequestBuilder.AcceptEncoding) is decoded,
+limited with DecompressMaxSize.
 */
 func (r *ResponseWrapper) Text() string {
+	if err := r.decompress(DecompressMaxSize); err != nil {
+		return ""
+	}
+
 	return string(errorsx.Ignore(io.ReadAll(r.Body)))
 }
 
 /*
 Unmarshal detects response type and decodes it into target.
 Have an optional mime parameter to force response type.
+Encoded body (see RequestBuilder.AcceptEncoding) is decoded,
+limited with DecompressMaxSize.
 If response type is not supported, or there is an error during decoding,
 chain execution will be stopped.
 Returns wrapper for chaining.
@@ -121,6 +134,11 @@ func (r *ResponseWrapper) Unmarshal(target any, mime ...string) *ResponseWrapper
 	if len(mime) > 0 {
 		r.Header.Set("Content-Type", mime[0])
 	}
+	// Decode body
+	if err := r.decompress(DecompressMaxSize); err != nil {
+		r.err = err
+		return r
+	}
 	// Process response
 	switch strings.Split(r.Header.Get("Content-Type"), ";")[0] {
 	case "application/json":
@@ -157,5 +175,5 @@ func Response(resp *http.Response, err ...error) *ResponseWrapper {
 		err = append(err, nil)
 	}
 
-	return &ResponseWrapper{resp, err[0]}
+	return &ResponseWrapper{Response: resp, err: err[0]}
 }
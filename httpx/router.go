@@ -0,0 +1,315 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+/*
+Middleware is a http.Handler wrapper,
+used by Router to extend request handling.
+*/
+type Middleware func(http.Handler) http.Handler
+
+/*
+Router is a small trie-based http router.
+It supports method routing, path parameters ("{id}"),
+wildcards ("{path...}", only as the last segment), route groups and middlewares.
+Path parameters are stored in the request context
+and can be accessed with PathParams or Bind.
+
+Static segments have priority over parameters,
+parameters have priority over wildcards.
+If path is matched, but method is not, Router responds with 405
+and "Allow" header. HEAD requests are falling back to GET handlers.
+
+Usage:
+
+	router := httpx.NewRouter()
+	router.Use(Logger)
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := httpx.PathParams(r).Int("id")
+		...
+	})
+	router.Group("/admin", func(g *httpx.Router) {
+		g.Use(Auth)
+		g.Delete("/users/{id}", ...)
+	})
+	http.ListenAndServe(":8000", router)
+*/
+type Router struct {
+	// NotFound is called if no route matched. Defaults to http.NotFound
+	NotFound http.Handler
+	// MethodNotAllowed is called if path matched, but method didn't.
+	// Defaults to plain 405 response
+	MethodNotAllowed http.Handler
+
+	root       *routerNode
+	prefix     string
+	middleware []Middleware
+}
+
+// routerNode is a single trie node.
+type routerNode struct {
+	static   map[string]*routerNode
+	param    *routerNode
+	wildcard *routerNode
+	name     string
+	handlers map[string]http.Handler
+}
+
+/*
+Use appends middlewares to the router (or group).
+Middlewares are applied to routes, registered after the call.
+Root router middlewares are also wrapping NotFound and MethodNotAllowed handlers.
+*/
+func (r *Router) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+/*
+Group creates a sub-router with a given path prefix.
+Group inherits parent middlewares,
+but middlewares added to the group are not affecting parent.
+*/
+func (r *Router) Group(prefix string, fn func(g *Router)) *Router {
+	group := &Router{
+		root:       r.tree(),
+		prefix:     r.prefix + strings.TrimSuffix(prefix, "/"),
+		middleware: append([]Middleware{}, r.middleware...),
+	}
+	if fn != nil {
+		fn(group)
+	}
+
+	return group
+}
+
+/*
+Handle registers a handler for a given method and pattern.
+Empty method means any method.
+Panics on conflicting parameter names or invalid wildcard placement.
+*/
+func (r *Router) Handle(method, pattern string, handler http.Handler) {
+	// Wrap handler with middlewares
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	// Walk through the trie, creating missing nodes
+	segments := Path(r.prefix + pattern).Tokens()
+	node := r.tree()
+	for i, segment := range segments {
+		kind, name := pathSegment(segment)
+		switch kind {
+		case pathSegmentWildcard:
+			if i != len(segments)-1 {
+				panic(fmt.Sprintf("wildcard must be the last segment: %s", pattern))
+			}
+			node.wildcard = routerChild(node.wildcard, name, pattern)
+			node = node.wildcard
+		case pathSegmentParam:
+			node.param = routerChild(node.param, name, pattern)
+			node = node.param
+		default:
+			if node.static == nil {
+				node.static = map[string]*routerNode{}
+			}
+			if node.static[segment] == nil {
+				node.static[segment] = &routerNode{}
+			}
+			node = node.static[segment]
+		}
+	}
+	// Register handler
+	if node.handlers == nil {
+		node.handlers = map[string]http.Handler{}
+	}
+	node.handlers[strings.ToUpper(method)] = handler
+}
+
+// routerChild returns existing parameter node or creates a new one.
+func routerChild(node *routerNode, name, pattern string) *routerNode {
+	if node == nil {
+		return &routerNode{name: name}
+	}
+	if node.name != name {
+		panic(fmt.Sprintf("conflicting parameter name %s (%s already registered): %s", name, node.name, pattern))
+	}
+
+	return node
+}
+
+/*
+HandleFunc registers a handler function for a given method and pattern.
+*/
+func (r *Router) HandleFunc(method, pattern string, fn http.HandlerFunc) {
+	r.Handle(method, pattern, fn)
+}
+
+// Get registers a GET handler function.
+func (r *Router) Get(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodGet, pattern, fn)
+}
+
+// Post registers a POST handler function.
+func (r *Router) Post(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodPost, pattern, fn)
+}
+
+// Put registers a PUT handler function.
+func (r *Router) Put(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodPut, pattern, fn)
+}
+
+// Patch registers a PATCH handler function.
+func (r *Router) Patch(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodPatch, pattern, fn)
+}
+
+// Delete registers a DELETE handler function.
+func (r *Router) Delete(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodDelete, pattern, fn)
+}
+
+/*
+ServeHTTP implements http.Handler.
+*/
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	params := Params{}
+	tokens := Path(req.URL.Path).Tokens()
+	node := routerLookup(r.tree(), tokens, params, req.Method)
+	// Not found, or method not allowed
+	if node == nil {
+		node = routerLookup(r.tree(), tokens, Params{}, "")
+		if node == nil {
+			r.fallback(handlerOr(r.NotFound, http.NotFoundHandler())).ServeHTTP(w, req)
+			return
+		}
+		w.Header().Set("Allow", strings.Join(routerAllow(node), ", "))
+		r.fallback(handlerOr(r.MethodNotAllowed, http.HandlerFunc(routerMethodNotAllowed))).ServeHTTP(w, req)
+		return
+	}
+	// Serve
+	if len(params) > 0 {
+		req = WithPathParams(req, params)
+	}
+	node.handler(req.Method).ServeHTTP(w, req)
+}
+
+// handler resolves a node handler by method, nil if not allowed.
+// HEAD falls back to GET, any method falls back to a method-less handler.
+func (n *routerNode) handler(method string) http.Handler {
+	handler := n.handlers[method]
+	if handler == nil && method == http.MethodHead {
+		handler = n.handlers[http.MethodGet]
+	}
+	if handler == nil {
+		handler = n.handlers[""]
+	}
+
+	return handler
+}
+
+// tree returns trie root, initializing it if needed.
+func (r *Router) tree() *routerNode {
+	if r.root == nil {
+		r.root = &routerNode{}
+	}
+
+	return r.root
+}
+
+// fallback wraps a fallback handler with router middlewares.
+func (r *Router) fallback(handler http.Handler) http.Handler {
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+
+	return handler
+}
+
+/*
+routerLookup finds a node with a handler for given path tokens and method
+(or with any handlers, if method is empty), filling params on the way.
+Static segments are checked first, then parameters, then wildcards,
+so a parameter route still serves a method, missing on a static sibling.
+*/
+func routerLookup(node *routerNode, tokens []string, params Params, method string) *routerNode {
+	// End of the path
+	if len(tokens) == 0 {
+		if !node.allows(method) {
+			return nil
+		}
+
+		return node
+	}
+	// Static
+	if child := node.static[tokens[0]]; child != nil {
+		if found := routerLookup(child, tokens[1:], params, method); found != nil {
+			return found
+		}
+	}
+	// Param
+	if node.param != nil && tokens[0] != "" {
+		if found := routerLookup(node.param, tokens[1:], params, method); found != nil {
+			params[node.param.name] = tokens[0]
+			return found
+		}
+	}
+	// Wildcard
+	if node.wildcard != nil && node.wildcard.allows(method) {
+		params[node.wildcard.name] = strings.Join(tokens, "/")
+		return node.wildcard
+	}
+
+	return nil
+}
+
+// allows checks whether node serves a method (or any, if method is empty).
+func (n *routerNode) allows(method string) bool {
+	if method == "" {
+		return n.handlers != nil
+	}
+
+	return n.handler(method) != nil
+}
+
+// routerAllow lists methods, allowed for a node.
+func routerAllow(node *routerNode) []string {
+	methods := make([]string, 0, len(node.handlers)+1)
+	for method := range node.handlers {
+		methods = append(methods, method)
+	}
+	if node.handlers[http.MethodGet] != nil && node.handlers[http.MethodHead] == nil {
+		methods = append(methods, http.MethodHead)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+// routerMethodNotAllowed is a default 405 handler.
+func routerMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// handlerOr returns handler, or fallback if handler is nil.
+func handlerOr(handler, fallback http.Handler) http.Handler {
+	if handler != nil {
+		return handler
+	}
+
+	return fallback
+}
+
+/*
+NewRouter is a Router constructor.
+Check Router for details.
+*/
+func NewRouter() *Router {
+	return &Router{
+		root: &routerNode{},
+	}
+}
@@ -0,0 +1,145 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// routerTestHandler responds with a given name and path params.
+func routerTestHandler(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := PathParams(r)
+		fmt.Fprint(w, name)
+		for _, key := range []string{"id", "path"} {
+			if value, ok := params[key]; ok {
+				fmt.Fprintf(w, " %s=%s", key, value)
+			}
+		}
+	}
+}
+
+// routerTestServe serves a request and returns the recorder.
+func routerTestServe(router http.Handler, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+
+	return recorder
+}
+
+func TestRouter(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users", routerTestHandler("list"))
+	router.Get("/users/new", routerTestHandler("new"))
+	router.Get("/users/{id}", routerTestHandler("get"))
+	router.Post("/users/{id}", routerTestHandler("update"))
+	router.Get("/files/{path...}", routerTestHandler("files"))
+	router.HandleFunc("", "/any", routerTestHandler("any"))
+	tests := []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{"GET", "/users", 200, "list"},
+		{"GET", "/users/new", 200, "new"},
+		{"GET", "/users/42", 200, "get id=42"},
+		{"POST", "/users/42", 200, "update id=42"},
+		// Static node without method falls back to parameter sibling
+		{"POST", "/users/new", 200, "update id=new"},
+		{"HEAD", "/users/42", 200, ""},
+		{"DELETE", "/users/42", 405, ""},
+		{"GET", "/files/a/b/c.txt", 200, "files path=a/b/c.txt"},
+		{"PATCH", "/any", 200, "any"},
+		{"GET", "/missing", 404, ""},
+		{"GET", "/users/42/missing", 404, ""},
+	}
+	for _, test := range tests {
+		recorder := routerTestServe(router, test.method, test.path)
+		if recorder.Code != test.status {
+			t.Fatalf("%s %s: expected status %d, got %d", test.method, test.path, test.status, recorder.Code)
+		}
+		if test.status == 200 && test.method != "HEAD" && recorder.Body.String() != test.body {
+			t.Fatalf("%s %s: expected %q, got %q", test.method, test.path, test.body, recorder.Body.String())
+		}
+	}
+	// Allow header
+	recorder := routerTestServe(router, "DELETE", "/users/42")
+	if allow := recorder.Header().Get("Allow"); allow != "GET, HEAD, POST" {
+		t.Fatalf("expected Allow header, got %q", allow)
+	}
+}
+
+func TestRouterGroupMiddleware(t *testing.T) {
+	var order []string
+	middleware := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	router := NewRouter()
+	router.Use(middleware("root1"), middleware("root2"))
+	router.Get("/public", routerTestHandler("public"))
+	router.Group("/admin", func(g *Router) {
+		g.Use(middleware("group"))
+		g.Get("/users/{id}", routerTestHandler("admin"))
+	})
+	router.Get("/after", routerTestHandler("after"))
+	tests := []struct {
+		path  string
+		body  string
+		order string
+	}{
+		{"/admin/users/7", "admin id=7", "root1,root2,group"},
+		{"/public", "public", "root1,root2"},
+		{"/after", "after", "root1,root2"},
+		{"/missing", "", "root1,root2"},
+	}
+	for _, test := range tests {
+		order = nil
+		recorder := routerTestServe(router, "GET", test.path)
+		if test.body != "" && recorder.Body.String() != test.body {
+			t.Fatalf("%s: expected %q, got %q", test.path, test.body, recorder.Body.String())
+		}
+		if strings.Join(order, ",") != test.order {
+			t.Fatalf("%s: expected middleware order %s, got %v", test.path, test.order, order)
+		}
+	}
+}
+
+func TestRouterCustomFallbacks(t *testing.T) {
+	router := NewRouter()
+	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	router.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})
+	router.Get("/item", routerTestHandler("item"))
+	if code := routerTestServe(router, "GET", "/missing").Code; code != http.StatusTeapot {
+		t.Fatalf("expected custom not found, got %d", code)
+	}
+	if code := routerTestServe(router, "POST", "/item").Code; code != http.StatusConflict {
+		t.Fatalf("expected custom method not allowed, got %d", code)
+	}
+}
+
+func TestRouterConflicts(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/{id}", routerTestHandler("get"))
+	for _, pattern := range []string{"/users/{name}", "/files/{path...}/tail"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: expected panic", pattern)
+				}
+			}()
+			router.Get(pattern, routerTestHandler("conflict"))
+		}()
+	}
+}
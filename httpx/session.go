@@ -0,0 +1,275 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+/*
+CSRF extracts anti-CSRF tokens from responses
+and re-injects them into subsequent unsafe requests (POST, PUT, PATCH, DELETE)
+to the same host.
+Token is extracted from a cookie, a response header, or a html meta tag
+(in this order, first found wins).
+Within a Session, token cookie is read from the session jar as well,
+so it's injected after Session.Load.
+
+Usage:
+
+	session := httpx.NewSession()
+	session.CSRF = &httpx.CSRF{Cookie: "XSRF-TOKEN", Header: "X-XSRF-TOKEN"}
+*/
+type CSRF struct {
+	// Cookie is a cookie name with token, e.g. "csrftoken"
+	Cookie string
+	// ResponseHeader is a response header name with token
+	ResponseHeader string
+	// Meta is a html meta tag name with token. Defaults to "csrf-token"
+	Meta string
+	// Header is a request header name to inject token. Defaults to "X-CSRF-Token"
+	Header string
+
+	lock   sync.Mutex
+	tokens map[string]string
+}
+
+/*
+Token returns a last extracted token for a host.
+*/
+func (c *CSRF) Token(host string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.tokens[strings.ToLower(host)]
+}
+
+/*
+SetToken sets a token for a host manually.
+*/
+func (c *CSRF) SetToken(host, token string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.tokens == nil {
+		c.tokens = map[string]string{}
+	}
+	c.tokens[strings.ToLower(host)] = token
+}
+
+// csrfMeta matches html meta tags.
+var csrfMeta = regexp.MustCompile(`(?i)<meta\s[^>]*>`)
+
+// csrfAttr matches html tag attributes.
+var csrfAttr = regexp.MustCompile(`(?i)([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// extract finds a token in response.
+func (c *CSRF) extract(resp *http.Response) (string, error) {
+	// Cookie
+	if c.Cookie != "" {
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == c.Cookie && cookie.Value != "" {
+				return url.QueryUnescape(cookie.Value)
+			}
+		}
+	}
+	// Header
+	if c.ResponseHeader != "" {
+		if token := resp.Header.Get(c.ResponseHeader); token != "" {
+			return token, nil
+		}
+	}
+	// Html meta tag, body is buffered and restored
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") || resp.Header.Get("Content-Encoding") != "" {
+		return "", nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	meta := c.Meta
+	if meta == "" {
+		meta = "csrf-token"
+	}
+	for _, tag := range csrfMeta.FindAll(body, -1) {
+		attrs := map[string]string{}
+		for _, attr := range csrfAttr.FindAllSubmatch(tag, -1) {
+			attrs[strings.ToLower(string(attr[1]))] = string(attr[2]) + string(attr[3])
+		}
+		if strings.EqualFold(attrs["name"], meta) && attrs["content"] != "" {
+			return attrs["content"], nil
+		}
+	}
+
+	return "", nil
+}
+
+// stored returns a token from a jar cookie, if enabled.
+func (c *CSRF) stored(jar *CookieJar, u *url.URL) string {
+	if jar == nil || c.Cookie == "" {
+		return ""
+	}
+	for _, cookie := range jar.Match(u) {
+		if cookie.Name == c.Cookie && cookie.Value != "" {
+			if token, err := url.QueryUnescape(cookie.Value); err == nil {
+				return token
+			}
+		}
+	}
+
+	return ""
+}
+
+/*
+Transport is a ClientMiddleware.
+*/
+func (c *CSRF) Transport(next http.RoundTripper) http.RoundTripper {
+	return c.transport(next, nil)
+}
+
+// transport is a Transport, which also looks for a token cookie in a jar
+// (f.e. restored with Session.Load).
+func (c *CSRF) transport(next http.RoundTripper, jar *CookieJar) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		// Inject token
+		header := c.Header
+		if header == "" {
+			header = "X-CSRF-Token"
+		}
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			token := c.stored(jar, req.URL)
+			if token == "" {
+				token = c.Token(req.URL.Host)
+			}
+			if token != "" && req.Header.Get(header) == "" {
+				req = req.Clone(req.Context())
+				req.Header.Set(header, token)
+			}
+		}
+		// Execute
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		// Extract token
+		token, err := c.extract(resp)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			c.SetToken(req.URL.Host, token)
+		}
+
+		return resp, nil
+	})
+}
+
+/*
+Session is a sticky client session.
+It wraps a client with a persistent cookie jar
+and optional CSRF tokens handling.
+
+Usage:
+
+	session := httpx.NewSession()
+	session.CSRF = &httpx.CSRF{Cookie: "csrftoken", Header: "X-CSRFToken"}
+	session.Load("session.json")
+	defer session.Save("session.json")
+
+	session.Request("GET", "https://example.com/login").Do()
+	session.Request("POST", "https://example.com/login").BodyForm(credentials).Do()
+	session.Cookies("https://example.com") // inspect cookies
+*/
+type Session struct {
+	// Jar is a session cookie jar
+	Jar *CookieJar
+	// CSRF enables CSRF tokens handling, if set
+	CSRF *CSRF
+
+	client *http.Client
+}
+
+/*
+Client returns a session client.
+*/
+func (s *Session) Client() *http.Client {
+	return s.client
+}
+
+/*
+Request initializes a *RequestBuilder, which uses session client.
+*/
+func (s *Session) Request(method, href string) *RequestBuilder {
+	return Request(method, href).Client(s.client)
+}
+
+/*
+Cookies returns cookies, which would be sent to a given url.
+*/
+func (s *Session) Cookies(href string) []JarCookie {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil
+	}
+
+	return s.Jar.Match(u)
+}
+
+/*
+SetCookie sets a cookie for a given url, following the same rules as received cookies.
+*/
+func (s *Session) SetCookie(href string, cookie *http.Cookie) error {
+	u, err := url.Parse(href)
+	if err != nil {
+		return err
+	}
+	s.Jar.SetCookies(u, []*http.Cookie{cookie})
+
+	return nil
+}
+
+/*
+Save persists session cookies into a json file.
+*/
+func (s *Session) Save(path string) error {
+	return s.Jar.Save(path)
+}
+
+/*
+Load restores session cookies from a json file.
+*/
+func (s *Session) Load(path string) error {
+	return s.Jar.Load(path)
+}
+
+// transport injects CSRF handling, if enabled.
+func (s *Session) transport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if s.CSRF == nil {
+			return next.RoundTrip(req)
+		}
+		return s.CSRF.transport(next, s.Jar).RoundTrip(req)
+	})
+}
+
+/*
+NewSession is a Session constructor.
+Middlewares are applied after session ones (see Client).
+*/
+func NewSession(middleware ...ClientMiddleware) *Session {
+	session := &Session{Jar: NewCookieJar()}
+	session.client = &http.Client{
+		Jar:       session.Jar,
+		Transport: Chain(nil, append([]ClientMiddleware{session.transport}, middleware...)...),
+	}
+
+	return session
+}
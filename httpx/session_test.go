@@ -0,0 +1,82 @@
+package httpx
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// sessionTestServer is a client middleware, which responds instead of a server.
+type sessionTestServer struct {
+	lock     sync.Mutex
+	requests []*http.Request
+	cookies  map[string]string // path -> Set-Cookie
+}
+
+func (s *sessionTestServer) Transport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.requests = append(s.requests, req)
+		header := http.Header{}
+		if cookie, ok := s.cookies[req.URL.Path]; ok {
+			header.Set("Set-Cookie", cookie)
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: http.NoBody, Request: req}, nil
+	})
+}
+
+// last returns the last received request.
+func (s *sessionTestServer) last() *http.Request {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.requests[len(s.requests)-1]
+}
+
+func TestSessionLoginCookie(t *testing.T) {
+	server := &sessionTestServer{cookies: map[string]string{
+		"/login": "session=secret; Domain=example.com; Path=/",
+	}}
+	session := NewSession(server.Transport)
+	if err := session.Request("POST", "https://www.example.com/login").Do().Error(); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Request("GET", "https://www.example.com/account").Do().Error(); err != nil {
+		t.Fatal(err)
+	}
+	cookie, err := server.last().Cookie("session")
+	if err != nil || cookie.Value != "secret" {
+		t.Fatalf("expected login cookie to be sent, got %v", server.last().Header)
+	}
+}
+
+func TestSessionCSRFAfterLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	server := &sessionTestServer{cookies: map[string]string{
+		"/form": "csrftoken=token%2B1; Path=/",
+	}}
+	// Receive token and persist it
+	session := NewSession(server.Transport)
+	session.CSRF = &CSRF{Cookie: "csrftoken", Header: "X-CSRFToken"}
+	if err := session.Request("GET", "https://example.com/form").Do().Error(); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	// Restore in a new session
+	session = NewSession(server.Transport)
+	session.CSRF = &CSRF{Cookie: "csrftoken", Header: "X-CSRFToken"}
+	if err := session.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Request("POST", "https://example.com/submit").Do().Error(); err != nil {
+		t.Fatal(err)
+	}
+	if token := server.last().Header.Get("X-CSRFToken"); token != "token+1" {
+		t.Fatalf("expected restored token to be injected, got %q", token)
+	}
+}
@@ -0,0 +1,211 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSSEUnsupported = errors.New("response writer doesn't support flushing")
+	ErrSSEClosed      = errors.New("event stream is closed")
+)
+
+/*
+Event is a single Server-Sent Event.
+Retry is a reconnection time, sent to client (zero means not set).
+*/
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+/*
+SSEWriter writes Server-Sent Events into a response.
+It keeps the connection alive with periodic heartbeat comments
+and exposes client "Last-Event-ID" header to resume the stream.
+Send is safe for concurrent use.
+Close is mandatory (usually deferred right after creation),
+otherwise heartbeats might be written after the handler returns.
+
+Usage:
+
+	func Handler(w http.ResponseWriter, r *http.Request) {
+		sse, err := httpx.NewSSEWriter(w, r, 15 * time.Second)
+		if err != nil {
+			httpx.WriteError(w, err)
+			return
+		}
+		defer sse.Close()
+		// Resume from the last event, received by client
+		for _, e := range EventsAfter(sse.LastEventID) {
+			if err := sse.Send(e); err != nil {
+				return
+			}
+		}
+	}
+*/
+type SSEWriter struct {
+	// LastEventID holds client "Last-Event-ID" header value
+	LastEventID string
+
+	w       http.ResponseWriter
+	flusher http.Flusher
+	lock    sync.Mutex
+	closed  bool
+	done    chan struct{} // closed on stop
+	stopped chan struct{} // closed on heartbeat exit
+}
+
+/*
+Send writes an event and flushes it to the client.
+Multiline data is split into multiple "data" fields.
+*/
+func (s *SSEWriter) Send(e Event) error {
+	// Compose event
+	b := strings.Builder{}
+	if e.ID != "" {
+		b.WriteString("id: " + sseClean(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + sseClean(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(e.Data, "\r\n", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	// Write
+	return s.write(b.String())
+}
+
+/*
+SendJSON writes an event with json-encoded data.
+*/
+func (s *SSEWriter) SendJSON(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.Send(Event{Event: event, Data: string(data)})
+}
+
+/*
+Comment writes a comment line, ignored by clients.
+*/
+func (s *SSEWriter) Comment(text string) error {
+	return s.write(": " + sseClean(text) + "\n\n")
+}
+
+/*
+Close stops heartbeats, waits for heartbeat goroutine to exit
+and prevents further writes.
+It must be called before handler returns.
+It doesn't close underlying connection,
+which is done on handler return.
+*/
+func (s *SSEWriter) Close() {
+	s.stop()
+	<-s.stopped
+}
+
+// stop prevents further writes and signals heartbeat to exit.
+func (s *SSEWriter) stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+}
+
+// write writes a raw chunk and flushes it.
+func (s *SSEWriter) write(chunk string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return ErrSSEClosed
+	}
+	if _, err := fmt.Fprint(s.w, chunk); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+
+	return nil
+}
+
+// heartbeat sends heartbeat comments until writer or request is done.
+func (s *SSEWriter) heartbeat(r *http.Request, interval time.Duration) {
+	defer close(s.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-r.Context().Done():
+			s.stop()
+			return
+		case <-ticker.C:
+			if err := s.Comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sseClean removes line breaks from single-line fields.
+func sseClean(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+/*
+NewSSEWriter prepares response for Server-Sent Events
+and returns SSEWriter for it.
+Heartbeat comments are sent with a given interval (zero disables heartbeats)
+until request context is done or writer is closed.
+Returned writer must be closed with Close before handler returns
+(defer sse.Close()).
+Returns ErrSSEUnsupported if response writer doesn't support flushing.
+*/
+func NewSSEWriter(w http.ResponseWriter, r *http.Request, heartbeat time.Duration) (*SSEWriter, error) {
+	// Ensure flushing support
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrSSEUnsupported
+	}
+	// Write headers
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	// Build writer
+	sse := &SSEWriter{
+		LastEventID: r.Header.Get("Last-Event-ID"),
+		w:           w,
+		flusher:     flusher,
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	if heartbeat > 0 {
+		go sse.heartbeat(r, heartbeat)
+	} else {
+		close(sse.stopped)
+	}
+
+	return sse, nil
+}
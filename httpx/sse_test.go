@@ -0,0 +1,45 @@
+package httpx
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEWriterClose(t *testing.T) {
+	var (
+		recorder = httptest.NewRecorder()
+		request  = httptest.NewRequest("GET", "/events", nil)
+	)
+	sse, err := NewSSEWriter(recorder, request, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Hop-by-hop headers are not set (forbidden in HTTP/2)
+	if recorder.Header().Get("Connection") != "" {
+		t.Fatalf("expected no Connection header, got %q", recorder.Header().Get("Connection"))
+	}
+	if err := sse.Send(Event{ID: "1", Data: "first\nsecond"}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	sse.Close()
+	// Heartbeat goroutine is done, nothing is written after Close
+	body := recorder.Body.String()
+	time.Sleep(10 * time.Millisecond)
+	if recorder.Body.String() != body {
+		t.Fatalf("expected no writes after Close")
+	}
+	if !strings.Contains(body, "id: 1\ndata: first\ndata: second\n\n") {
+		t.Fatalf("expected event in body, got %q", body)
+	}
+	if !strings.Contains(body, ": heartbeat\n\n") {
+		t.Fatalf("expected heartbeat in body, got %q", body)
+	}
+	if err := sse.Send(Event{Data: "late"}); !errors.Is(err, ErrSSEClosed) {
+		t.Fatalf("expected ErrSSEClosed, got %v", err)
+	}
+	sse.Close()
+}
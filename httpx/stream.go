@@ -0,0 +1,242 @@
+package httpx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+// StreamMaxLineSize is a default line size limit for Lines, NDJSON and Events.
+var StreamMaxLineSize = 1 << 20
+
+/*
+Stream is an iterator over values, decoded from a response body
+(or any other source).
+Usage is similar to bufio.Scanner.
+Stream is closed automatically on exhaustion, error or context cancellation,
+but it's a good practice to defer Close anyway.
+
+Usage:
+
+	stream := httpx.Request("GET", "https://example.com/feed").Do().Lines()
+	defer stream.Close()
+	for stream.Next() {
+		log.Println(stream.Value())
+	}
+	if err := stream.Err(); err != nil {
+		...
+	}
+*/
+type Stream[T any] struct {
+	ctx   context.Context //nolint:containedctx
+	next  func() (T, error)
+	close func() error
+
+	value T
+	err   error
+	once  sync.Once
+	done  chan struct{}
+}
+
+/*
+Next advances the stream to the next value,
+which will be available with Value.
+Returns false on exhaustion or error.
+*/
+func (s *Stream[T]) Next() bool {
+	// Check stream status
+	select {
+	case <-s.done:
+		s.setErr(nil)
+		return false
+	default:
+	}
+	// Get next value
+	value, err := s.next()
+	if err != nil {
+		s.setErr(err)
+		s.Close() //nolint:errcheck
+		return false
+	}
+	s.value = value
+
+	return true
+}
+
+// setErr stores the first non-EOF error, preferring context error.
+func (s *Stream[T]) setErr(err error) {
+	if s.err != nil {
+		return
+	}
+	if s.ctx != nil && s.ctx.Err() != nil {
+		err = s.ctx.Err()
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.err = err
+	}
+}
+
+/*
+Value returns the current value.
+*/
+func (s *Stream[T]) Value() T {
+	return s.value
+}
+
+/*
+Err returns the first non-EOF error, occurred during iteration.
+*/
+func (s *Stream[T]) Err() error {
+	return s.err
+}
+
+/*
+Close stops the stream and releases underlying resources.
+Safe to call multiple times.
+*/
+func (s *Stream[T]) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		if s.close != nil {
+			err = s.close()
+		}
+	})
+
+	return err
+}
+
+/*
+Collect reads all remaining values into a slice and closes the stream.
+*/
+func (s *Stream[T]) Collect() ([]T, error) {
+	defer s.Close() //nolint:errcheck
+
+	values := []T{}
+	for s.Next() {
+		values = append(values, s.Value())
+	}
+
+	return values, s.Err()
+}
+
+/*
+NewStream creates a Stream from a next function.
+Next function must return io.EOF on exhaustion.
+Close function (optional) is called once on stream close.
+Stream is closed on context cancellation.
+*/
+func NewStream[T any](ctx context.Context, next func() (T, error), close func() error) *Stream[T] {
+	stream := &Stream[T]{
+		ctx:   ctx,
+		next:  next,
+		close: close,
+		done:  make(chan struct{}),
+	}
+	// Close on context cancellation.
+	// Closing is unblocking pending body reads.
+	if ctx != nil && ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				stream.Close() //nolint:errcheck
+			case <-stream.done:
+			}
+		}()
+	}
+
+	return stream
+}
+
+// streamScanner creates a line scanner with a size limit.
+func streamScanner(body io.Reader, maxsize []int) *bufio.Scanner {
+	limit := StreamMaxLineSize
+	if len(maxsize) > 0 && maxsize[0] > 0 {
+		limit = maxsize[0]
+	}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), limit)
+
+	return scanner
+}
+
+// streamContext returns response request context, if any.
+func (r *ResponseWrapper) streamContext() context.Context {
+	if r.Response != nil && r.Request != nil {
+		return r.Request.Context()
+	}
+
+	return context.Background()
+}
+
+// streamError returns a stream, which immediately fails with a given error.
+func streamError[T any](err error) *Stream[T] {
+	return NewStream(nil, func() (T, error) {
+		var zero T
+		return zero, err
+	}, nil)
+}
+
+/*
+Lines streams response body line by line.
+Optional parameter limits line size (StreamMaxLineSize by default).
+Chain error (if any) is returned by stream Err.
+*/
+func (r *ResponseWrapper) Lines(maxsize ...int) *Stream[string] {
+	// Check error status
+	if r.err != nil {
+		return streamError[string](r.err)
+	}
+	// Decode body, size is limited per line
+	if err := r.decompress(0); err != nil {
+		return streamError[string](err)
+	}
+	// Build stream
+	scanner := streamScanner(r.Body, maxsize)
+	return NewStream(r.streamContext(), func() (string, error) {
+		if !scanner.Scan() {
+			return "", errOr(scanner.Err(), io.EOF)
+		}
+		return scanner.Text(), nil
+	}, r.Body.Close)
+}
+
+/*
+NDJSON streams newline-delimited json response body,
+decoding each line into T. Empty lines are skipped.
+Optional parameter limits line size (StreamMaxLineSize by default).
+
+Usage:
+
+	stream := httpx.NDJSON[Item](httpx.Request("GET", "https://example.com/items").Do())
+	defer stream.Close()
+	for stream.Next() {
+		item := stream.Value()
+	}
+*/
+func NDJSON[T any](r *ResponseWrapper, maxsize ...int) *Stream[T] {
+	lines := r.Lines(maxsize...)
+	return NewStream(nil, func() (T, error) {
+		var value T
+		for lines.Next() {
+			if len(lines.Value()) == 0 {
+				continue
+			}
+			err := json.Unmarshal([]byte(lines.Value()), &value)
+			return value, err
+		}
+		return value, errOr(lines.Err(), io.EOF)
+	}, lines.Close)
+}
+
+// errOr returns err, or fallback if err is nil.
+func errOr(err, fallback error) error {
+	if err != nil {
+		return err
+	}
+
+	return fallback
+}
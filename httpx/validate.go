@@ -0,0 +1,301 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	ErrValidateTarget = errors.New("failed to validate, non struct type is given")
+	ErrValidateRule   = errors.New("invalid validation rule")
+)
+
+// validatePatterns caches compiled pattern rules.
+var validatePatterns sync.Map
+
+/*
+ValidationError describes a single failed validation rule.
+*/
+type ValidationError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+/*
+ValidationErrors is a list of failed validation rules,
+returned by Validate and Bind.
+*/
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, verr := range e {
+		messages[i] = verr.Field + " " + verr.Message
+	}
+
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+/*
+Write writes validation errors as a json response
+with 422 Unprocessable Entity status.
+
+Usage:
+
+	var verrs httpx.ValidationErrors
+	if errors.As(err, &verrs) {
+		verrs.Write(w)
+		return
+	}
+*/
+func (e ValidationErrors) Write(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]any{"errors": e}) //nolint:errchkjson,errcheck
+}
+
+/*
+Validate checks struct fields against declarative "validate" tag rules.
+Nested structs are validated recursively.
+Returns ValidationErrors if at least one rule failed.
+
+Supported rules:
+
+  - required - value must not be zero (pointer must not be nil).
+  - min=n, max=n - number bounds, or length bounds for strings, slices and maps.
+  - oneof=a b c - value must be one of space-separated options.
+  - pattern=regexp - value must match regular expression.
+    Must be the last rule, because it may contain commas.
+
+Rules oneof and pattern are skipped for empty values,
+combine them with required if needed.
+Field names in errors are taken from json/query/form/path/header/cookie tags,
+falling back to Go field name.
+Invalid rule definition (unknown rule, bad parameter, min/max for unsupported type)
+is a programmer error, Validate returns ErrValidateRule for it
+instead of ValidationErrors.
+
+Usage:
+
+	type Params struct {
+		Name  string `json:"name" validate:"required,max=64"`
+		Role  string `json:"role" validate:"oneof=admin user"`
+		Email string `json:"email" validate:"pattern=^.+@.+$"`
+	}
+
+	err := httpx.Validate(&Params{})
+*/
+func Validate(target any) error {
+	ob := reflect.Indirect(reflect.ValueOf(target))
+	if ob.Kind() != reflect.Struct {
+		return ErrValidateTarget
+	}
+
+	errs := ValidationErrors{}
+	if err := validateStruct(ob, "", &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateStruct validates struct fields recursively.
+// Returns an error only for invalid rule definitions.
+func validateStruct(ob reflect.Value, prefix string, errs *ValidationErrors) error {
+	typ := ob.Type()
+	for i := 0; i < ob.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := ob.Field(i)
+		name := validateFieldName(field)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		// Validate field rules
+		if rules := field.Tag.Get("validate"); rules != "" && rules != "-" {
+			if err := validateRules(fv, name, rules, errs); err != nil {
+				return err
+			}
+		}
+		// Validate nested structs
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct && codecIsNested(fv.Type()) {
+			nested := name
+			if field.Anonymous {
+				nested = prefix
+			}
+			if err := validateStruct(fv, nested, errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateFieldName resolves a field name, visible for a client.
+func validateFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "form", "path", "header", "cookie"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// validateRules checks a single value against rules definition.
+func validateRules(v reflect.Value, name, rules string, errs *ValidationErrors) error {
+	parts := strings.Split(rules, ",")
+	for i := 0; i < len(parts); i++ {
+		rule, param, _ := strings.Cut(parts[i], "=")
+		// Pattern consumes the rest of the definition
+		if rule == "pattern" {
+			param = strings.TrimPrefix(strings.Join(parts[i:], ","), "pattern=")
+			i = len(parts)
+		}
+		msg, err := validateRule(v, rule, param)
+		if err != nil {
+			return fmt.Errorf("%w: field %s: %s", ErrValidateRule, name, err)
+		}
+		if msg != "" {
+			*errs = append(*errs, ValidationError{
+				Field:   name,
+				Rule:    rule,
+				Param:   param,
+				Message: msg,
+			})
+		}
+	}
+
+	return nil
+}
+
+// validateRule checks a single rule and returns a failure message, if any.
+// Error is returned for invalid rule definition.
+func validateRule(v reflect.Value, rule, param string) (string, error) {
+	// Required is the only rule, which checks nil pointers
+	if rule == "required" {
+		if v.IsZero() {
+			return "is required", nil
+		}
+
+		return "", nil
+	}
+	// Dereference pointers, other rules are ignoring nil values
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	// Check rules
+	switch rule {
+	case "min", "max":
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s rule parameter: %s", rule, param)
+		}
+		size, islen, err := validateSize(v)
+		if err != nil {
+			return "", err
+		}
+		if rule == "min" && size < bound {
+			return validateSizeMessage(islen, "at least", param), nil
+		}
+		if rule == "max" && size > bound {
+			return validateSizeMessage(islen, "at most", param), nil
+		}
+	case "oneof":
+		val, _ := codecFormat(v)
+		if val == "" {
+			return "", nil
+		}
+		for _, option := range strings.Fields(param) {
+			if val == option {
+				return "", nil
+			}
+		}
+
+		return "must be one of: " + strings.Join(strings.Fields(param), ", "), nil
+	case "pattern":
+		re, err := validatePattern(param)
+		if err != nil {
+			return "", err
+		}
+		val, _ := codecFormat(v)
+		if val == "" {
+			return "", nil
+		}
+		if !re.MatchString(val) {
+			return "must match pattern " + param, nil
+		}
+	default:
+		return "", fmt.Errorf("unknown rule: %s", rule)
+	}
+
+	return "", nil
+}
+
+// validateSize returns a number value, or a length for sized types.
+func validateSize(v reflect.Value) (float64, bool, error) {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, nil
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true, nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true, nil
+	default:
+		return 0, false, fmt.Errorf("min/max rules are not supported for %s", v.Type())
+	}
+}
+
+// validateSizeMessage composes min/max failure message.
+func validateSizeMessage(islen bool, bound, param string) string {
+	if islen {
+		return "length must be " + bound + " " + param
+	}
+
+	return "must be " + bound + " " + param
+}
+
+// validatePattern compiles and caches a pattern rule.
+func validatePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := validatePatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil //nolint:forcetypeassert
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	validatePatterns.Store(pattern, re)
+
+	return re, nil
+}
@@ -0,0 +1,76 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type validateTestParams struct {
+	Name  string `json:"name" validate:"required,max=4"`
+	Role  string `json:"role" validate:"oneof=admin user"`
+	Email string `json:"email" validate:"pattern=^.+@.+$"`
+}
+
+func TestValidateRules(t *testing.T) {
+	if err := Validate(&validateTestParams{Name: "joe", Role: "user", Email: "joe@example.com"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err := Validate(&validateTestParams{Name: "joseph", Role: "root", Email: "joe"})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 3 {
+		t.Fatalf("expected 3 validation errors, got %v", err)
+	}
+	if status := ErrorStatus(err); status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", status)
+	}
+}
+
+func TestValidateInvalidRules(t *testing.T) {
+	targets := []any{
+		&struct {
+			Name string `validate:"unknown"`
+		}{},
+		&struct {
+			Name string `validate:"max=many"`
+		}{},
+		&struct {
+			Flag bool `validate:"min=1"`
+		}{Flag: true},
+		&struct {
+			Name string `validate:"pattern=("`
+		}{},
+	}
+	for _, target := range targets {
+		err := Validate(target)
+		if !errors.Is(err, ErrValidateRule) {
+			t.Fatalf("expected ErrValidateRule for %T, got %v", target, err)
+		}
+		if status := ErrorStatus(err); status != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d", status)
+		}
+	}
+}
+
+func TestErrorStatusBindTarget(t *testing.T) {
+	if status := ErrorStatus(ErrBindTarget); status != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", status)
+	}
+}
+
+func TestErrorStatusInvalid(t *testing.T) {
+	for _, status := range []int{0, 42, 600, 1000} {
+		err := ErrorWithStatus(status, errors.New("invalid"))
+		if got := ErrorStatus(err); got != http.StatusInternalServerError {
+			t.Fatalf("status %d: expected 500, got %d", status, got)
+		}
+		w := httptest.NewRecorder()
+		if err := WriteError(w, &Problem{Status: status}); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("problem status %d: expected 500, got %d", status, w.Code)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+WriteJSON writes a value as a json response with a given status.
+*/
+func WriteJSON(w http.ResponseWriter, status int, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+
+	return err
+}
+
+/*
+Write writes a value with a given status,
+choosing response format with request "Accept" header.
+Supported formats are json (default), xml and plain text.
+If client accepts nothing from the list, json is used.
+
+Usage:
+
+	httpx.Write(w, r, http.StatusOK, data)
+*/
+func Write(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	switch writeNegotiate(r.Header.Get("Accept")) {
+	case "application/xml":
+		data, err := xml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(status)
+		_, err = w.Write(data)
+
+		return err
+	case "text/plain":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		_, err := fmt.Fprint(w, v)
+
+		return err
+	default:
+		return WriteJSON(w, status, v)
+	}
+}
+
+// writeNegotiate picks the most preferred supported mime type from Accept header.
+func writeNegotiate(accept string) string {
+	type candidate struct {
+		mime string
+		q    float64
+	}
+	candidates := []candidate{}
+	for _, part := range strings.Split(accept, ",") {
+		mimetype, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qv, ok := params["q"]; ok {
+			q, _ = strconv.ParseFloat(qv, 64)
+		}
+		switch mimetype {
+		case "application/json", "*/*", "application/*":
+			candidates = append(candidates, candidate{"application/json", q})
+		case "application/xml", "text/xml":
+			candidates = append(candidates, candidate{"application/xml", q})
+		case "text/plain", "text/*":
+			candidates = append(candidates, candidate{"text/plain", q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	if len(candidates) == 0 || candidates[0].q <= 0 {
+		return "application/json"
+	}
+
+	return candidates[0].mime
+}
+
+/*
+WriteStream copies a reader into response with a given status and content type,
+flushing after each chunk if writer supports it.
+Useful for proxying or generating long responses, like NDJSON.
+*/
+func WriteStream(w http.ResponseWriter, status int, contenttype string, src io.Reader) error {
+	w.Header().Set("Content-Type", contenttype)
+	w.WriteHeader(status)
+
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+/*
+Problem is an RFC 7807 problem details object.
+It implements error, so handlers might return it as is
+and write it with WriteError.
+Extensions are merged into the resulting json object.
+*/
+type Problem struct {
+	Type       string         `json:"type,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Status     int            `json:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Title + ": " + p.Detail
+	}
+
+	return p.Title
+}
+
+/*
+HTTPStatus returns problem status.
+*/
+func (p *Problem) HTTPStatus() int {
+	return p.Status
+}
+
+/*
+MarshalJSON implements problem marshalling with extensions.
+*/
+func (p Problem) MarshalJSON() ([]byte, error) {
+	data := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		data[k] = v
+	}
+	type problem Problem
+	base, err := json.Marshal(problem(p))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(base, &data); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(data)
+}
+
+/*
+WriteProblem writes an RFC 7807 problem response
+with "application/problem+json" content type.
+Missing or invalid status defaults to 500, missing title defaults to status text.
+*/
+func WriteProblem(w http.ResponseWriter, p Problem) error {
+	if p.Status < 100 || p.Status > 599 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_, err = w.Write(data)
+
+	return err
+}
+
+/*
+StatusError is an error with attached http status.
+*/
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+/*
+HTTPStatus returns attached status.
+*/
+func (e *StatusError) HTTPStatus() int {
+	return e.Status
+}
+
+/*
+ErrorWithStatus attaches http status to an error,
+which will be used by WriteError.
+*/
+func ErrorWithStatus(status int, err error) error {
+	return &StatusError{Status: status, Err: err}
+}
+
+/*
+ErrorStatus maps an error to http status.
+
+  - Errors with "HTTPStatus() int" method (like *StatusError or *Problem) are using own status,
+    if it's valid (100-599).
+  - ValidationErrors are mapped to 422.
+  - Bind decoding errors are mapped to 400.
+  - context.DeadlineExceeded is mapped to 504.
+  - Other errors are mapped to 500, including programmer errors
+    (ErrBindTarget, ErrValidateTarget, ErrValidateRule).
+*/
+func ErrorStatus(err error) int {
+	var (
+		serr interface{ HTTPStatus() int }
+		verr ValidationErrors
+		ferr *FieldError
+		jerr *json.SyntaxError
+		terr *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &serr):
+		// Invalid statuses would panic on WriteHeader
+		if status := serr.HTTPStatus(); status >= 100 && status <= 599 {
+			return status
+		}
+		return http.StatusInternalServerError
+	case errors.As(err, &verr):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, &ferr), errors.As(err, &jerr), errors.As(err, &terr):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+/*
+WriteError writes an error as an RFC 7807 problem response.
+Status is resolved with ErrorStatus.
+Error message is exposed as problem detail only for 4xx statuses,
+to avoid leaking internal details.
+*Problem errors are written as is,
+ValidationErrors are exposed with "errors" extension.
+
+Usage:
+
+	if err := httpx.Bind(r, &input); err != nil {
+		httpx.WriteError(w, err)
+		return
+	}
+*/
+func WriteError(w http.ResponseWriter, err error) error {
+	// Write problems as is
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return WriteProblem(w, *problem)
+	}
+	// Compose problem
+	p := Problem{Status: ErrorStatus(err)}
+	if p.Status < http.StatusInternalServerError {
+		p.Detail = err.Error()
+	}
+	var verr ValidationErrors
+	if errors.As(err, &verr) {
+		p.Detail = "validation failed"
+		p.Extensions = map[string]any{"errors": verr}
+	}
+
+	return WriteProblem(w, p)
+}
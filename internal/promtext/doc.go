@@ -0,0 +1,5 @@
+/*
+promtext - an internal package with Prometheus text exposition format helpers,
+shared by metrics exporters of other packages (httpx, cache).
+*/
+package promtext
@@ -0,0 +1,73 @@
+package promtext
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentType is a Prometheus text exposition format content type.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelReplacer escapes label values.
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+/*
+Label quotes a label value.
+*/
+func Label(value string) string {
+	return `"` + labelReplacer.Replace(value) + `"`
+}
+
+/*
+Float formats a sample value.
+*/
+func Float(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+/*
+Header writes metric HELP and TYPE lines.
+*/
+func Header(w io.Writer, metric, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric, help, metric, kind)
+}
+
+/*
+Sample writes a metric sample line.
+Labels are already formatted pairs (name=Label(value)), might be empty.
+*/
+func Sample(w io.Writer, metric, labels, value string) {
+	if labels == "" {
+		fmt.Fprintf(w, "%s %s\n", metric, value)
+		return
+	}
+	fmt.Fprintf(w, "%s{%s} %s\n", metric, labels, value)
+}
+
+/*
+Histogram writes cumulative histogram samples (buckets, sum and count).
+Counts are cumulative counts for each bucket bound.
+*/
+func Histogram(w io.Writer, metric, labels string, bounds []float64, counts []uint64, sum float64, count uint64) {
+	prefix := labels
+	if prefix != "" {
+		prefix += ","
+	}
+	for i, bound := range bounds {
+		Sample(w, metric+"_bucket", prefix+"le="+Label(Float(bound)), strconv.FormatUint(counts[i], 10))
+	}
+	Sample(w, metric+"_bucket", prefix+`le="+Inf"`, strconv.FormatUint(count, 10))
+	Sample(w, metric+"_sum", labels, Float(sum))
+	Sample(w, metric+"_count", labels, strconv.FormatUint(count, 10))
+}
+
+/*
+Serve writes metrics into HTTP response with a proper content type.
+*/
+func Serve(w http.ResponseWriter, metrics io.WriterTo) {
+	w.Header().Set("Content-Type", ContentType)
+	metrics.WriteTo(w) //nolint:errcheck
+}